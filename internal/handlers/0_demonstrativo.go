@@ -0,0 +1,410 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type linhaDemonstrativoContabil struct {
+	Quadro            string  `json:"quadro"`
+	Codigo            string  `json:"codigo"`
+	Descricao         string  `json:"descricao"`
+	Nivel             int     `json:"nivel"`
+	ExercicioAtual    float64 `json:"exercicio_atual"`
+	ExercicioAnterior float64 `json:"exercicio_anterior"`
+} // @name LinhaDemonstrativoContabil
+
+type demonstrativoContabil struct {
+	Demonstrativo        string
+	AnoExercicio         int
+	AnoExercicioAnterior int
+	Linhas               []linhaDemonstrativoContabil
+} // @name DemonstrativoContabil
+
+/*** Dados Estáticos ***/
+// Valores das contas que podem compor uma linha de demonstrativo, com o sinal
+// do FIP215 (positivo é credor). Os débitos do período entram como negativos,
+// para que as linhas de natureza D os apresentem como positivos.
+var valoresLinhaDemonstrativo = map[string]func(dadoRelatorioFIP215) float64{
+	"saldo_atual":    func(d dadoRelatorioFIP215) float64 { return d.SaldoAtual },
+	"saldo_anterior": func(d dadoRelatorioFIP215) float64 { return d.SaldoAnterior },
+	"movimento":      func(d dadoRelatorioFIP215) float64 { return d.ValorCredito - d.ValorDebito },
+	"credito":        func(d dadoRelatorioFIP215) float64 { return d.ValorCredito },
+	"debito":         func(d dadoRelatorioFIP215) float64 { return -d.ValorDebito },
+}
+
+/*** Dados Estáticos ***/
+
+// demonstrativoContabilHandler lê os parâmetros do FIP215, confere se o
+// demonstrativo está configurado nas regras do exercício atual e do anterior e
+// emite o demonstrativo em JSON ou CSV.
+func demonstrativoContabilHandler(c echo.Context, nome string) error {
+	/*** Parâmetros ***/
+	parametros := struct {
+		parametrosRelatorioFIP215
+
+		// Adicionais
+		Formato string
+	}{}
+	/*** Parâmetros ***/
+
+	/*** Validação dos Parâmetros ***/
+	var erros []string
+
+	parametros.parametrosRelatorioFIP215, erros = lerParametrosRelatorioFIP215(c, true)
+
+	parametros.Formato = "json"
+
+	if err := echo.QueryParamsBinder(c).String("formato", &parametros.Formato).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o formato de saída no parâmetro 'formato'.")
+	}
+
+	if err := Validate.Var(parametros.Formato, "oneof=json csv"); err != nil {
+		erros = append(erros, "Por favor, forneça um formato de saída válido (json ou csv) para o parâmetro 'formato'.")
+	}
+
+	if len(erros) == 0 {
+		if _, ok := parametros.Regras.Demonstrativos[nome]; !ok {
+			erros = append(erros, fmt.Sprintf("O demonstrativo %s não está configurado nas regras do exercício %d.", nome, parametros.AnoExercicio))
+		}
+
+		if _, ok := RegrasDoExercicio(parametros.AnoExercicio - 1); !ok {
+			erros = append(erros, fmt.Sprintf("Não há regras de negócio configuradas para o exercício %d.", parametros.AnoExercicio-1))
+		}
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	erros, erro := codigosInexistentesRelatorioFIP215(parametros.parametrosRelatorioFIP215)
+
+	if erro != nil {
+		return erro
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	demonstrativo, erro := consultarDemonstrativoContabil(nome, parametros.parametrosRelatorioFIP215)
+
+	if erro != nil {
+		return erro
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	/*** Lógica Adicional ***/
+	if parametros.Formato == "json" {
+		return c.JSON(http.StatusOK, demonstrativo)
+	}
+
+	conteudo, err := demonstrativo.CSV()
+
+	if err != nil {
+		log.Printf("demonstrativoContabilHandler: %v", err)
+		return ErroGeracaoArquivo
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s_%d.csv\"", nome, demonstrativo.AnoExercicio))
+	/*** Lógica Adicional ***/
+
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", conteudo)
+}
+
+// CSV gera o demonstrativo separado por ponto e vírgula, com uma linha por
+// linha do demonstrativo e as colunas dos dois exercícios.
+func (d demonstrativoContabil) CSV() ([]byte, error) {
+	var conteudo bytes.Buffer
+
+	escritor := csv.NewWriter(&conteudo)
+	escritor.Comma = ';'
+
+	registros := [][]string{{"QUADRO", "CODIGO", "DESCRICAO", "NIVEL", fmt.Sprintf("EXERCICIO_%d", d.AnoExercicio), fmt.Sprintf("EXERCICIO_%d", d.AnoExercicioAnterior)}}
+
+	for _, linha := range d.Linhas {
+		registros = append(registros, []string{
+			linha.Quadro,
+			linha.Codigo,
+			linha.Descricao,
+			strconv.Itoa(linha.Nivel),
+			strconv.FormatFloat(linha.ExercicioAtual, 'f', 2, 64),
+			strconv.FormatFloat(linha.ExercicioAnterior, 'f', 2, 64),
+		})
+	}
+
+	if err := escritor.WriteAll(registros); err != nil {
+		return nil, err
+	}
+
+	return conteudo.Bytes(), nil
+}
+
+// validarDemonstrativo confere as linhas de um demonstrativo das regras do
+// exercício: códigos únicos, naturezas e valores conhecidos e referências a
+// linhas existentes, sem ciclos.
+func validarDemonstrativo(demonstrativo demonstrativoExercicio) error {
+	if demonstrativo.MesContabilExercicioAnterior < 1 || demonstrativo.MesContabilExercicioAnterior > 4 {
+		return fmt.Errorf("o mês contábil do exercício anterior %d não está entre 1 e 4", demonstrativo.MesContabilExercicioAnterior)
+	}
+
+	linhas := map[string]linhaDemonstrativo{}
+
+	for _, linha := range demonstrativo.Linhas {
+		if linha.Codigo == "" {
+			return fmt.Errorf("a linha %q não tem código", linha.Descricao)
+		}
+
+		if _, ok := linhas[linha.Codigo]; ok {
+			return fmt.Errorf("a linha %s está repetida", linha.Codigo)
+		}
+
+		if linha.Natureza != "D" && linha.Natureza != "C" {
+			return fmt.Errorf("a natureza %q da linha %s não é D nem C", linha.Natureza, linha.Codigo)
+		}
+
+		if _, ok := valoresLinhaDemonstrativo[linha.Valor]; linha.Valor != "" && !ok {
+			return fmt.Errorf("o valor %q da linha %s não é conhecido", linha.Valor, linha.Codigo)
+		}
+
+		if linha.IndicativoSuperavitFinanceiro < 0 || linha.IndicativoSuperavitFinanceiro > 2 {
+			return fmt.Errorf("o indicativo de superávit financeiro %d da linha %s não está entre 0 e 2", linha.IndicativoSuperavitFinanceiro, linha.Codigo)
+		}
+
+		linhas[linha.Codigo] = linha
+	}
+
+	// 0: não visitada / 1: em visita / 2: visitada.
+	estados := map[string]int{}
+
+	var visitar func(codigo string) error
+
+	visitar = func(codigo string) error {
+		estados[codigo] = 1
+
+		for _, referencia := range linhas[codigo].Linhas {
+			referencia, _ = sinalReferenciaDemonstrativo(referencia)
+
+			if _, ok := linhas[referencia]; !ok {
+				return fmt.Errorf("a linha %s referencia a linha inexistente %s", codigo, referencia)
+			}
+
+			switch estados[referencia] {
+			case 1:
+				return fmt.Errorf("a linha %s referencia a linha %s em ciclo", codigo, referencia)
+			case 0:
+				if err := visitar(referencia); err != nil {
+					return err
+				}
+			}
+		}
+
+		estados[codigo] = 2
+
+		return nil
+	}
+
+	for _, linha := range demonstrativo.Linhas {
+		if estados[linha.Codigo] == 0 {
+			if err := visitar(linha.Codigo); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sinalReferenciaDemonstrativo separa o "-" inicial de um prefixo de conta ou
+// de um código de linha, retornando o sinal da parcela.
+func sinalReferenciaDemonstrativo(referencia string) (string, float64) {
+	if codigo, ok := strings.CutPrefix(referencia, "-"); ok {
+		return codigo, -1
+	}
+
+	return referencia, 1
+}
+
+// parametrosBalanceteDemonstrativo ajusta os parâmetros do FIP215 para que o
+// balancete traga todas as contas analíticas do consolidado, sem os filtros de
+// apresentação.
+func parametrosBalanceteDemonstrativo(parametros parametrosRelatorioFIP215) parametrosRelatorioFIP215 {
+	parametros.Agrupamento = "consolidado"
+	parametros.NivelMaximo = 0
+	parametros.ContaInicio = ""
+	parametros.ContaFim = ""
+	parametros.PrefixoConta = ""
+	parametros.SuprimirZerados = false
+	parametros.SomenteMovimento = false
+
+	return parametros
+}
+
+// periodoBalanceteDemonstrativo ajusta os parâmetros do FIP215 para o
+// balancete de mesInicio a mesFim do exercício informado, com as regras desse
+// exercício.
+func periodoBalanceteDemonstrativo(parametros parametrosRelatorioFIP215, anoExercicio, mesInicio, mesFim, mesContabil int) parametrosRelatorioFIP215 {
+	if anoExercicio != parametros.AnoExercicio {
+		parametros.AnoExercicio = anoExercicio
+		parametros.Regras, _ = RegrasDoExercicio(anoExercicio)
+	}
+
+	parametros.MesContabil = mesContabil
+	parametros.MesInicio = mesInicio
+	parametros.MesFim = mesFim
+	parametros.MesSaldoAnteriorNome = MesParaNome[mesInicio-1]
+	parametros.Meses = nil
+
+	for i := mesInicio; i <= mesFim; i++ {
+		parametros.Meses = append(parametros.Meses, MesParaNome[i])
+	}
+
+	return parametros
+}
+
+// consultarDemonstrativoContabil monta o demonstrativo das regras do exercício
+// a partir do balancete do período pedido (exercício atual) e do balancete de
+// janeiro a dezembro do exercício anterior, com os mesmos filtros.
+func consultarDemonstrativoContabil(nome string, parametros parametrosRelatorioFIP215) (demonstrativoContabil, *echo.HTTPError) {
+	configuracao := parametros.Regras.Demonstrativos[nome]
+
+	atual := parametrosBalanceteDemonstrativo(parametros)
+	anterior := periodoBalanceteDemonstrativo(atual, atual.AnoExercicio-1, 1, 12, configuracao.MesContabilExercicioAnterior)
+
+	demonstrativo := demonstrativoContabil{
+		Demonstrativo:        nome,
+		AnoExercicio:         atual.AnoExercicio,
+		AnoExercicioAnterior: anterior.AnoExercicio,
+	}
+
+	var valores [2]map[string]float64
+
+	for i, parametrosExercicio := range []parametrosRelatorioFIP215{atual, anterior} {
+		balancetes, erro := consultarBalancetesDemonstrativo(configuracao.Linhas, parametrosExercicio)
+
+		if erro != nil {
+			return demonstrativo, erro
+		}
+
+		valores[i] = calcularLinhasDemonstrativo(configuracao.Linhas, balancetes)
+	}
+
+	for _, linha := range configuracao.Linhas {
+		demonstrativo.Linhas = append(demonstrativo.Linhas, linhaDemonstrativoContabil{
+			Quadro:            linha.Quadro,
+			Codigo:            linha.Codigo,
+			Descricao:         linha.Descricao,
+			Nivel:             linha.Nivel,
+			ExercicioAtual:    math.Round(valores[0][linha.Codigo]*100.0) / 100.0,
+			ExercicioAnterior: math.Round(valores[1][linha.Codigo]*100.0) / 100.0,
+		})
+	}
+
+	return demonstrativo, nil
+}
+
+// consultarBalancetesDemonstrativo consulta um balancete para cada indicativo
+// de superávit financeiro usado nas linhas com contas; o indicativo 0 mantém o
+// filtro pedido.
+func consultarBalancetesDemonstrativo(linhas []linhaDemonstrativo, parametros parametrosRelatorioFIP215) (map[int][]dadoRelatorioFIP215, *echo.HTTPError) {
+	balancetes := map[int][]dadoRelatorioFIP215{}
+
+	for _, linha := range linhas {
+		if _, ok := balancetes[linha.IndicativoSuperavitFinanceiro]; ok || len(linha.Contas) == 0 {
+			continue
+		}
+
+		parametrosBalancete := parametros
+
+		if linha.IndicativoSuperavitFinanceiro != 0 {
+			parametrosBalancete.IndicativoSuperavitFinanceiro = linha.IndicativoSuperavitFinanceiro
+		}
+
+		balancete, erro := consultarRelatorioFIP215(parametrosBalancete)
+
+		if erro != nil {
+			return nil, erro
+		}
+
+		balancetes[linha.IndicativoSuperavitFinanceiro] = balancete.Dados
+	}
+
+	return balancetes, nil
+}
+
+// calcularLinhasDemonstrativo calcula o valor de cada linha a partir das contas
+// analíticas dos balancetes, por indicativo de superávit financeiro, e das
+// linhas referenciadas, que podem vir antes ou depois no demonstrativo.
+func calcularLinhasDemonstrativo(linhas []linhaDemonstrativo, balancetes map[int][]dadoRelatorioFIP215) map[string]float64 {
+	definicoes := map[string]linhaDemonstrativo{}
+
+	for _, linha := range linhas {
+		definicoes[linha.Codigo] = linha
+	}
+
+	valores := map[string]float64{}
+
+	var calcular func(codigo string) float64
+
+	calcular = func(codigo string) float64 {
+		if valor, ok := valores[codigo]; ok {
+			return valor
+		}
+
+		linha := definicoes[codigo]
+
+		valorConta := valoresLinhaDemonstrativo["saldo_atual"]
+
+		if linha.Valor != "" {
+			valorConta = valoresLinhaDemonstrativo[linha.Valor]
+		}
+
+		var valor float64
+
+		for _, contaContabil := range balancetes[linha.IndicativoSuperavitFinanceiro] {
+			if !contaContabil.ContaEscrituravel {
+				continue
+			}
+
+			codigoConta := strings.ReplaceAll(contaContabil.CodigoContaContabil, ".", "")
+
+			for _, conta := range linha.Contas {
+				prefixo, sinal := sinalReferenciaDemonstrativo(conta)
+
+				if strings.HasPrefix(codigoConta, prefixo) {
+					valor += sinal * valorConta(contaContabil)
+				}
+			}
+		}
+
+		// A comparação com zero evita o -0 nas linhas devedoras sem saldo.
+		if linha.Natureza == "D" && valor != 0 {
+			valor = -valor
+		}
+
+		for _, referencia := range linha.Linhas {
+			referencia, sinal := sinalReferenciaDemonstrativo(referencia)
+			valor += sinal * calcular(referencia)
+		}
+
+		valores[codigo] = valor
+
+		return valor
+	}
+
+	for _, linha := range linhas {
+		calcular(linha.Codigo)
+	}
+
+	return valores
+}
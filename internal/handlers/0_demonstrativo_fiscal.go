@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type linhaDemonstrativoFiscal struct {
+	Quadro    string    `json:"quadro"`
+	Codigo    string    `json:"codigo"`
+	Descricao string    `json:"descricao"`
+	Nivel     int       `json:"nivel"`
+	Valores   []float64 `json:"valores"`
+} // @name LinhaDemonstrativoFiscal
+
+type demonstrativoFiscal struct {
+	Demonstrativo string
+	AnoExercicio  int
+	Periodicidade string
+	Periodo       int
+	Colunas       []string
+	Linhas        []linhaDemonstrativoFiscal
+} // @name DemonstrativoFiscal
+
+// colunaDemonstrativoFiscal é uma coluna de um demonstrativo fiscal, calculada
+// sobre o balancete do período.
+type colunaDemonstrativoFiscal struct {
+	Nome    string
+	Periodo parametrosRelatorioFIP215
+}
+
+/*** Dados Estáticos ***/
+// Quantidade de meses de cada periodicidade dos demonstrativos fiscais: o RREO
+// é bimestral. A periodicidade é também o nome do parâmetro com o período.
+var mesesPeriodicidadeDemonstrativoFiscal = map[string]int{
+	"bimestre": 2,
+}
+
+/*** Dados Estáticos ***/
+
+// demonstrativoFiscalHandler lê os parâmetros do FIP215 e o bimestre, que
+// substitui o período do balancete, confere se as linhas do demonstrativo
+// contábil informado estão configuradas nas regras do exercício e emite o
+// demonstrativo fiscal com essas linhas e as colunas informadas em JSON ou CSV.
+func demonstrativoFiscalHandler(c echo.Context, nome, demonstrativoContabil, periodicidade string, colunas []string) error {
+	/*** Parâmetros ***/
+	parametros := struct {
+		parametrosRelatorioFIP215
+
+		// Adicionais
+		Periodo int
+		Formato string
+	}{}
+	/*** Parâmetros ***/
+
+	/*** Validação dos Parâmetros ***/
+	var erros []string
+
+	parametros.parametrosRelatorioFIP215, erros = lerParametrosRelatorioFIP215(c, false)
+
+	// O período vem do bimestre, e as linhas usam todas as contas analíticas
+	// do consolidado.
+	erros = append(erros, ParametrosNaoSuportados(c, "mes_referencia", "ate_mes_referencia", "mes_inicio", "mes_fim", "agrupamento", "nivel_maximo", "conta_inicio", "conta_fim", "prefixo_conta")...)
+
+	valueBinder := echo.QueryParamsBinder(c)
+
+	quantidadePeriodos := 12 / mesesPeriodicidadeDemonstrativoFiscal[periodicidade]
+
+	if err := valueBinder.Int(periodicidade, &parametros.Periodo).BindError(); err != nil {
+		erros = append(erros, fmt.Sprintf("Por favor, forneça o %s no parâmetro '%s'.", periodicidade, periodicidade))
+	} else if err := Validate.Var(parametros.Periodo, fmt.Sprintf("gte=1,lte=%d", quantidadePeriodos)); err != nil {
+		erros = append(erros, fmt.Sprintf("Por favor, forneça um %s válido entre 1 e %d para o parâmetro '%s'.", periodicidade, quantidadePeriodos, periodicidade))
+	}
+
+	parametros.Formato = "json"
+
+	if err := valueBinder.String("formato", &parametros.Formato).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o formato de saída no parâmetro 'formato'.")
+	}
+
+	if err := Validate.Var(parametros.Formato, "oneof=json csv"); err != nil {
+		erros = append(erros, "Por favor, forneça um formato de saída válido (json ou csv) para o parâmetro 'formato'.")
+	}
+
+	if len(erros) == 0 {
+		if _, ok := parametros.Regras.Demonstrativos[demonstrativoContabil]; !ok {
+			erros = append(erros, fmt.Sprintf("O demonstrativo %s não está configurado nas regras do exercício %d.", demonstrativoContabil, parametros.AnoExercicio))
+		}
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	erros, erro := codigosInexistentesRelatorioFIP215(parametros.parametrosRelatorioFIP215)
+
+	if erro != nil {
+		return erro
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	demonstrativo, erro := consultarDemonstrativoFiscal(nome, demonstrativoContabil, periodicidade, parametros.Periodo, colunas, parametros.parametrosRelatorioFIP215)
+
+	if erro != nil {
+		return erro
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	/*** Lógica Adicional ***/
+	if parametros.Formato == "json" {
+		return c.JSON(http.StatusOK, demonstrativo)
+	}
+
+	conteudo, err := demonstrativo.CSV()
+
+	if err != nil {
+		log.Printf("demonstrativoFiscalHandler: %v", err)
+		return ErroGeracaoArquivo
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s_%d_%s_%d.csv\"", nome, demonstrativo.AnoExercicio, periodicidade, demonstrativo.Periodo))
+	/*** Lógica Adicional ***/
+
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", conteudo)
+}
+
+// CSV gera o demonstrativo separado por ponto e vírgula, com uma linha por
+// linha do demonstrativo e uma coluna para cada coluna do demonstrativo.
+func (d demonstrativoFiscal) CSV() ([]byte, error) {
+	var conteudo bytes.Buffer
+
+	escritor := csv.NewWriter(&conteudo)
+	escritor.Comma = ';'
+
+	registros := [][]string{append([]string{"QUADRO", "CODIGO", "DESCRICAO", "NIVEL"}, d.Colunas...)}
+
+	for _, linha := range d.Linhas {
+		registro := []string{linha.Quadro, linha.Codigo, linha.Descricao, strconv.Itoa(linha.Nivel)}
+
+		for _, valor := range linha.Valores {
+			registro = append(registro, strconv.FormatFloat(valor, 'f', 2, 64))
+		}
+
+		registros = append(registros, registro)
+	}
+
+	if err := escritor.WriteAll(registros); err != nil {
+		return nil, err
+	}
+
+	return conteudo.Bytes(), nil
+}
+
+// colunasDemonstrativoFiscal monta as colunas do demonstrativo a partir do
+// período pedido:
+//   - periodo: os meses do bimestre;
+//   - ate_periodo: de janeiro até o fim do período.
+func colunasDemonstrativoFiscal(tipos []string, periodicidade string, periodo int, parametros parametrosRelatorioFIP215) []colunaDemonstrativoFiscal {
+	var colunas []colunaDemonstrativoFiscal
+
+	meses := mesesPeriodicidadeDemonstrativoFiscal[periodicidade]
+	mesFim := periodo * meses
+
+	for _, tipo := range tipos {
+		switch tipo {
+		case "periodo":
+			colunas = append(colunas, colunaDemonstrativoFiscal{
+				Nome:    fmt.Sprintf("%s_%d", strings.ToUpper(periodicidade), periodo),
+				Periodo: periodoBalanceteDemonstrativo(parametros, parametros.AnoExercicio, mesFim-meses+1, mesFim, parametros.MesContabil),
+			})
+		case "ate_periodo":
+			colunas = append(colunas, colunaDemonstrativoFiscal{
+				Nome:    fmt.Sprintf("ATE_%s_%d", strings.ToUpper(periodicidade), periodo),
+				Periodo: periodoBalanceteDemonstrativo(parametros, parametros.AnoExercicio, 1, mesFim, parametros.MesContabil),
+			})
+		}
+	}
+
+	return colunas
+}
+
+// consultarDemonstrativoFiscal monta o demonstrativo fiscal com as linhas do
+// demonstrativo contábil das regras do exercício e as colunas informadas,
+// consultando uma única vez os balancetes de cada período.
+func consultarDemonstrativoFiscal(nome, demonstrativoContabil, periodicidade string, periodo int, tipos []string, parametros parametrosRelatorioFIP215) (demonstrativoFiscal, *echo.HTTPError) {
+	configuracao := parametros.Regras.Demonstrativos[demonstrativoContabil]
+
+	parametros = parametrosBalanceteDemonstrativo(parametros)
+
+	demonstrativo := demonstrativoFiscal{
+		Demonstrativo: nome,
+		AnoExercicio:  parametros.AnoExercicio,
+		Periodicidade: periodicidade,
+		Periodo:       periodo,
+	}
+
+	colunas := colunasDemonstrativoFiscal(tipos, periodicidade, periodo, parametros)
+
+	balancetesPeriodos := map[string]map[int][]dadoRelatorioFIP215{}
+
+	var valores []map[string]float64
+
+	for _, coluna := range colunas {
+		chave := fmt.Sprintf("%d-%d", coluna.Periodo.MesInicio, coluna.Periodo.MesFim)
+
+		if _, ok := balancetesPeriodos[chave]; !ok {
+			balancetes, erro := consultarBalancetesDemonstrativo(configuracao.Linhas, coluna.Periodo)
+
+			if erro != nil {
+				return demonstrativo, erro
+			}
+
+			balancetesPeriodos[chave] = balancetes
+		}
+
+		demonstrativo.Colunas = append(demonstrativo.Colunas, coluna.Nome)
+		valores = append(valores, calcularLinhasDemonstrativo(configuracao.Linhas, balancetesPeriodos[chave]))
+	}
+
+	for _, linha := range configuracao.Linhas {
+		linhaFiscal := linhaDemonstrativoFiscal{
+			Quadro:    linha.Quadro,
+			Codigo:    linha.Codigo,
+			Descricao: linha.Descricao,
+			Nivel:     linha.Nivel,
+		}
+
+		for _, valoresColuna := range valores {
+			linhaFiscal.Valores = append(linhaFiscal.Valores, math.Round(valoresColuna[linha.Codigo]*100.0)/100.0)
+		}
+
+		demonstrativo.Linhas = append(demonstrativo.Linhas, linhaFiscal)
+	}
+
+	return demonstrativo, nil
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestColunasDemonstrativoFiscal(t *testing.T) {
+	parametros := parametrosRelatorioFIP215{AnoExercicio: 2023, MesContabil: 1}
+
+	// Cada período é descrito como "ano/mês inicial-mês final/mês contábil".
+	type coluna struct {
+		nome    string
+		periodo string
+	}
+
+	casos := []struct {
+		nome      string
+		tipos     []string
+		periodo   int
+		esperadas []coluna
+	}{
+		{
+			nome:    "período e acumulado até o período",
+			tipos:   []string{"periodo", "ate_periodo"},
+			periodo: 3,
+			esperadas: []coluna{
+				{"BIMESTRE_3", "2023/5-6/1"},
+				{"ATE_BIMESTRE_3", "2023/1-6/1"},
+			},
+		},
+		{
+			nome:    "primeiro bimestre",
+			tipos:   []string{"periodo", "ate_periodo"},
+			periodo: 1,
+			esperadas: []coluna{
+				{"BIMESTRE_1", "2023/1-2/1"},
+				{"ATE_BIMESTRE_1", "2023/1-2/1"},
+			},
+		},
+		{
+			nome:    "tipo de coluna desconhecido",
+			tipos:   []string{"ate_periodo", "mensal"},
+			periodo: 6,
+			esperadas: []coluna{
+				{"ATE_BIMESTRE_6", "2023/1-12/1"},
+			},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			var obtidas []coluna
+
+			for _, c := range colunasDemonstrativoFiscal(caso.tipos, "bimestre", caso.periodo, parametros) {
+				p := c.Periodo
+
+				if len(p.Meses) != p.MesFim-p.MesInicio+1 || p.Meses[0] != MesParaNome[p.MesInicio] || p.MesSaldoAnteriorNome != MesParaNome[p.MesInicio-1] {
+					t.Errorf("coluna %s: meses %v e mês anterior %q não correspondem ao período %d-%d", c.Nome, p.Meses, p.MesSaldoAnteriorNome, p.MesInicio, p.MesFim)
+				}
+
+				obtidas = append(obtidas, coluna{c.Nome, fmt.Sprintf("%d/%d-%d/%d", p.AnoExercicio, p.MesInicio, p.MesFim, p.MesContabil)})
+			}
+
+			if !reflect.DeepEqual(obtidas, caso.esperadas) {
+				t.Errorf("colunas = %v, esperado %v", obtidas, caso.esperadas)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCalcularLinhasDemonstrativo(t *testing.T) {
+	balancetes := map[int][]dadoRelatorioFIP215{
+		0: {
+			{CodigoContaContabil: "1.0.0.0.0.00.00", SaldoAtual: -150},
+			{CodigoContaContabil: "1.1.1.0.0.00.00", ContaEscrituravel: true, SaldoAnterior: -80, ValorCredito: 10, ValorDebito: 30, SaldoAtual: -100},
+			{CodigoContaContabil: "1.1.2.0.0.00.00", ContaEscrituravel: true, SaldoAtual: -50},
+			{CodigoContaContabil: "2.1.1.0.0.00.00", ContaEscrituravel: true, SaldoAtual: 60},
+		},
+		1: {
+			{CodigoContaContabil: "1.1.1.0.0.00.00", ContaEscrituravel: true, SaldoAtual: -30},
+		},
+	}
+
+	ativo := linhaDemonstrativo{Codigo: "ATIVO", Natureza: "D", Contas: []string{"1"}}
+	passivo := linhaDemonstrativo{Codigo: "PASSIVO", Natureza: "C", Contas: []string{"2"}}
+
+	casos := []struct {
+		nome      string
+		linhas    []linhaDemonstrativo
+		esperados map[string]float64
+	}{
+		{
+			nome:      "soma as contas analíticas pela natureza da linha",
+			linhas:    []linhaDemonstrativo{ativo, passivo},
+			esperados: map[string]float64{"ATIVO": 150, "PASSIVO": 60},
+		},
+		{
+			nome:      "subtrai os prefixos com sinal",
+			linhas:    []linhaDemonstrativo{{Codigo: "CAIXA", Natureza: "D", Contas: []string{"11", "-112"}}},
+			esperados: map[string]float64{"CAIXA": 100},
+		},
+		{
+			nome: "usa o valor indicado na linha",
+			linhas: []linhaDemonstrativo{
+				{Codigo: "MOVIMENTO", Natureza: "D", Valor: "movimento", Contas: []string{"111"}},
+				{Codigo: "CREDITO", Natureza: "C", Valor: "credito", Contas: []string{"111"}},
+				{Codigo: "DEBITO", Natureza: "C", Valor: "debito", Contas: []string{"111"}},
+				{Codigo: "ANTERIOR", Natureza: "D", Valor: "saldo_anterior", Contas: []string{"111"}},
+			},
+			esperados: map[string]float64{"MOVIMENTO": 20, "CREDITO": 10, "DEBITO": -30, "ANTERIOR": 80},
+		},
+		{
+			nome: "soma as linhas referenciadas depois no demonstrativo",
+			linhas: []linhaDemonstrativo{
+				{Codigo: "RESULTADO", Natureza: "C", Linhas: []string{"PASSIVO", "-ATIVO"}},
+				ativo,
+				passivo,
+			},
+			esperados: map[string]float64{"RESULTADO": -90},
+		},
+		{
+			nome:      "usa o balancete do indicativo de superávit financeiro",
+			linhas:    []linhaDemonstrativo{{Codigo: "FINANCEIRO", Natureza: "D", IndicativoSuperavitFinanceiro: 1, Contas: []string{"1"}}},
+			esperados: map[string]float64{"FINANCEIRO": 30},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			valores := calcularLinhasDemonstrativo(caso.linhas, balancetes)
+
+			for codigo, esperado := range caso.esperados {
+				if valor := valores[codigo]; math.Abs(valor-esperado) > 1e-9 || math.Signbit(valor) != math.Signbit(esperado) {
+					t.Errorf("linha %s = %v, esperado %v", codigo, valor, esperado)
+				}
+			}
+		})
+	}
+}
+
+func TestValidarDemonstrativo(t *testing.T) {
+	valida := []linhaDemonstrativo{
+		{Codigo: "ATIVO", Natureza: "D", Contas: []string{"1"}},
+		{Codigo: "PASSIVO", Natureza: "C", Contas: []string{"2"}, Valor: "movimento", IndicativoSuperavitFinanceiro: 2},
+		{Codigo: "RESULTADO", Natureza: "C", Linhas: []string{"PASSIVO", "-ATIVO"}},
+	}
+
+	casos := []struct {
+		nome          string
+		demonstrativo demonstrativoExercicio
+		erro          string
+	}{
+		{
+			nome:          "demonstrativo válido",
+			demonstrativo: demonstrativoExercicio{MesContabilExercicioAnterior: 1, Linhas: valida},
+		},
+		{
+			nome:          "mês contábil do exercício anterior inválido",
+			demonstrativo: demonstrativoExercicio{MesContabilExercicioAnterior: 5, Linhas: valida},
+			erro:          "mês contábil",
+		},
+		{
+			nome:          "linha sem código",
+			demonstrativo: demonstrativoExercicio{MesContabilExercicioAnterior: 1, Linhas: []linhaDemonstrativo{{Descricao: "Ativo", Natureza: "D"}}},
+			erro:          "não tem código",
+		},
+		{
+			nome:          "linha repetida",
+			demonstrativo: demonstrativoExercicio{MesContabilExercicioAnterior: 1, Linhas: []linhaDemonstrativo{valida[0], valida[0]}},
+			erro:          "repetida",
+		},
+		{
+			nome:          "natureza inválida",
+			demonstrativo: demonstrativoExercicio{MesContabilExercicioAnterior: 1, Linhas: []linhaDemonstrativo{{Codigo: "ATIVO", Natureza: "X"}}},
+			erro:          "natureza",
+		},
+		{
+			nome:          "valor desconhecido",
+			demonstrativo: demonstrativoExercicio{MesContabilExercicioAnterior: 1, Linhas: []linhaDemonstrativo{{Codigo: "ATIVO", Natureza: "D", Valor: "saldo"}}},
+			erro:          "valor",
+		},
+		{
+			nome:          "indicativo de superávit financeiro inválido",
+			demonstrativo: demonstrativoExercicio{MesContabilExercicioAnterior: 1, Linhas: []linhaDemonstrativo{{Codigo: "ATIVO", Natureza: "D", IndicativoSuperavitFinanceiro: 3}}},
+			erro:          "superávit",
+		},
+		{
+			nome:          "referência a linha inexistente",
+			demonstrativo: demonstrativoExercicio{MesContabilExercicioAnterior: 1, Linhas: []linhaDemonstrativo{{Codigo: "ATIVO", Natureza: "D", Linhas: []string{"-PASSIVO"}}}},
+			erro:          "inexistente",
+		},
+		{
+			nome: "referências em ciclo",
+			demonstrativo: demonstrativoExercicio{MesContabilExercicioAnterior: 1, Linhas: []linhaDemonstrativo{
+				{Codigo: "A", Natureza: "D", Linhas: []string{"B"}},
+				{Codigo: "B", Natureza: "D", Linhas: []string{"-A"}},
+			}},
+			erro: "ciclo",
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			err := validarDemonstrativo(caso.demonstrativo)
+
+			if caso.erro == "" && err != nil {
+				t.Errorf("erro inesperado: %v", err)
+			}
+
+			if caso.erro != "" && (err == nil || !strings.Contains(err.Error(), caso.erro)) {
+				t.Errorf("erro = %v, esperado um erro com %q", err, caso.erro)
+			}
+		})
+	}
+}
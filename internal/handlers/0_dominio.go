@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type itemDominio struct {
+	ID        int    `json:"id"`
+	Codigo    int    `json:"codigo"`
+	Descricao string `json:"descricao"`
+} // @name ItemDominio
+
+type itemDominioSimbolico struct {
+	Dominio string
+	Codigo  int
+}
+
+/*** Dados Estáticos ***/
+// Itens de domínio usados nos templates SQL. O ID_ITEM_DOMINIO muda entre
+// instalações do FIPLAN, mas o nome do domínio e o código do item não, então
+// os templates usam o nome simbólico (ex.: {{dominio "MES_CONTABIL_EXECUCAO"}})
+// e o ID é resolvido no banco de dados. Para forçar um ID em um ambiente
+// específico, defina a variável de ambiente DOMINIO_<NOME SIMBÓLICO>.
+var ItensDominioSimbolicos map[string]itemDominioSimbolico = map[string]itemDominioSimbolico{
+	"MES_CONTABIL_EXECUCAO":     {Dominio: "MES_CONTABIL", Codigo: 1},
+	"MES_CONTABIL_APURACAO":     {Dominio: "MES_CONTABIL", Codigo: 2},
+	"MES_CONTABIL_ENCERRAMENTO": {Dominio: "MES_CONTABIL", Codigo: 3},
+	"SIM":                       {Dominio: "SIM_NAO", Codigo: 1},
+	"NAO":                       {Dominio: "SIM_NAO", Codigo: 2},
+}
+
+/*** Dados Estáticos ***/
+
+/*** Erro ***/
+var errDominioInexistente = errors.New("o domínio não existe")
+
+/*** Erro ***/
+
+/*** Cache ***/
+var (
+	dominios      map[string][]itemDominio = map[string][]itemDominio{}
+	dominiosMutex sync.RWMutex
+)
+
+/*** Cache ***/
+
+// CarregarDominios lê toda a tabela ITEM_DOMINIO e substitui o cache de domínios.
+func CarregarDominios() error {
+	query := `SELECT DOMINIO.NM_DOMINIO, ITEM.ID_ITEM_DOMINIO, ITEM.CD_ITEM_DOMINIO, ITEM.DS_ITEM_DOMINIO
+	          FROM ITEM_DOMINIO ITEM
+	          INNER JOIN DOMINIO ON (DOMINIO.ID_DOMINIO = ITEM.ID_DOMINIO)
+	          ORDER BY DOMINIO.NM_DOMINIO, ITEM.CD_ITEM_DOMINIO`
+
+	novosDominios, err := consultarDominios(query)
+
+	if err != nil {
+		return err
+	}
+
+	dominiosMutex.Lock()
+	dominios = novosDominios
+	dominiosMutex.Unlock()
+
+	log.Printf("CarregarDominios: %d domínios carregados", len(novosDominios))
+
+	return nil
+}
+
+// ItensDominio retorna os itens de um domínio, consultando o banco de dados
+// caso o domínio ainda não esteja no cache.
+func ItensDominio(nome string) ([]itemDominio, error) {
+	dominiosMutex.RLock()
+	itens, ok := dominios[nome]
+	dominiosMutex.RUnlock()
+
+	if ok {
+		return itens, nil
+	}
+
+	query := `SELECT DOMINIO.NM_DOMINIO, ITEM.ID_ITEM_DOMINIO, ITEM.CD_ITEM_DOMINIO, ITEM.DS_ITEM_DOMINIO
+	          FROM ITEM_DOMINIO ITEM
+	          INNER JOIN DOMINIO ON (DOMINIO.ID_DOMINIO = ITEM.ID_DOMINIO)
+	          WHERE DOMINIO.NM_DOMINIO = :1
+	          ORDER BY ITEM.CD_ITEM_DOMINIO`
+
+	novosDominios, err := consultarDominios(query, nome)
+
+	if err != nil {
+		return nil, err
+	}
+
+	itens, ok = novosDominios[nome]
+
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", errDominioInexistente, nome)
+	}
+
+	dominiosMutex.Lock()
+	dominios[nome] = itens
+	dominiosMutex.Unlock()
+
+	return itens, nil
+}
+
+// IDItemDominioSimbolico resolve o ID_ITEM_DOMINIO de um nome simbólico de
+// ItensDominioSimbolicos.
+func IDItemDominioSimbolico(nome string) (int, error) {
+	if valor, ok := os.LookupEnv("DOMINIO_" + nome); ok {
+		return strconv.Atoi(valor)
+	}
+
+	simbolico, ok := ItensDominioSimbolicos[nome]
+
+	if !ok {
+		return 0, fmt.Errorf("o item de domínio simbólico '%s' não existe", nome)
+	}
+
+	itens, err := ItensDominio(simbolico.Dominio)
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, item := range itens {
+		if item.Codigo == simbolico.Codigo {
+			return item.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("o domínio '%s' não possui item com código %d", simbolico.Dominio, simbolico.Codigo)
+}
+
+func consultarDominios(query string, argumentos ...any) (map[string][]itemDominio, error) {
+	compactQuery := strings.Join(strings.Fields(query), " ")
+	log.Printf("consultarDominios: %s", compactQuery)
+	rows, err := Db.Query(compactQuery, argumentos...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	novosDominios := map[string][]itemDominio{}
+
+	for rows.Next() {
+		var nome string
+		var item itemDominio
+
+		if err := rows.Scan(&nome, &item.ID, &item.Codigo, &item.Descricao); err != nil {
+			return nil, err
+		}
+
+		novosDominios[nome] = append(novosDominios[nome], item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return novosDominios, nil
+}
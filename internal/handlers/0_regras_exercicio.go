@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// regraInformacoesComplementaresMSC define as informações complementares das
+// contas SICONFI que começam com o prefixo. Quando mais de uma regra cobre a
+// conta, prevalece a de prefixo mais longo.
+type regraInformacoesComplementaresMSC struct {
+	PrefixoConta          string   `json:"prefixo_conta"`
+	Obrigatorias          []string `json:"obrigatorias"`
+	Permitidas            []string `json:"permitidas"`
+	PermiteSaldoInvertido bool     `json:"permite_saldo_invertido"`
+} // @name RegraInformacoesComplementaresMSC
+
+// associacaoPoderOrgaoSICONFI associa um poder/órgão SICONFI (IC do tipo PO)
+// aos filtros do FIP215 que selecionam as suas unidades no FIPLAN.
+type associacaoPoderOrgaoSICONFI struct {
+	CodigoPoderOrgao      int   `json:"codigo_poder_orgao"`
+	TiposPoder            []int `json:"tipos_poder"`
+	Orgaos                []int `json:"orgaos"`
+	UnidadesOrcamentarias []int `json:"unidades_orcamentarias"`
+} // @name AssociacaoPoderOrgaoSICONFI
+
+// linhaDemonstrativo define uma linha de um demonstrativo contábil. O valor da
+// linha soma os valores das contas analíticas do FIP215 que começam com os
+// prefixos de Contas e os valores das Linhas informadas, e um "-" antes do
+// prefixo ou do código da linha subtrai o valor. A natureza D apresenta os
+// saldos devedores como positivos; a natureza C, os credores.
+type linhaDemonstrativo struct {
+	Quadro                        string   `json:"quadro"`
+	Codigo                        string   `json:"codigo"`
+	Descricao                     string   `json:"descricao"`
+	Nivel                         int      `json:"nivel"`
+	Natureza                      string   `json:"natureza"`
+	Valor                         string   `json:"valor,omitempty"`
+	IndicativoSuperavitFinanceiro int      `json:"indicativo_superavit_financeiro,omitempty"`
+	Contas                        []string `json:"contas,omitempty"`
+	Linhas                        []string `json:"linhas,omitempty"`
+} // @name LinhaDemonstrativo
+
+// demonstrativoExercicio define as linhas de um demonstrativo contábil e o mês
+// contábil do balancete de dezembro usado na coluna do exercício anterior.
+type demonstrativoExercicio struct {
+	MesContabilExercicioAnterior int                  `json:"mes_contabil_exercicio_anterior"`
+	Linhas                       []linhaDemonstrativo `json:"linhas"`
+} // @name DemonstrativoExercicio
+
+type regrasExercicio struct {
+	ExercicioInicial                       int                                 `json:"exercicio_inicial"`
+	ExercicioFinal                         int                                 `json:"exercicio_final"`
+	UnidadesOrcamentariasRPPS              []int                               `json:"unidades_orcamentarias_rpps"`
+	UnidadesOrcamentariasMinisterioPublico []int                               `json:"unidades_orcamentarias_ministerio_publico"`
+	InformacoesComplementaresMSC           []regraInformacoesComplementaresMSC `json:"informacoes_complementares_msc"`
+	PoderesOrgaosSICONFI                   []associacaoPoderOrgaoSICONFI       `json:"poderes_orgaos_siconfi"`
+	Demonstrativos                         map[string]demonstrativoExercicio   `json:"demonstrativos"`
+} // @name RegrasExercicio
+
+type arquivoRegrasExercicio struct {
+	Versao     string            `json:"versao"`
+	Exercicios []regrasExercicio `json:"exercicios"`
+} // @name ArquivoRegrasExercicio
+
+/*** Cache ***/
+var (
+	regras      arquivoRegrasExercicio
+	regrasMutex sync.RWMutex
+)
+
+/*** Cache ***/
+
+// CarregarRegrasExercicio lê o arquivo de regras de negócio por exercício,
+// indicado pela variável de ambiente REGRAS_EXERCICIO_ARQUIVO
+// (padrão: config/regras_exercicio.json).
+func CarregarRegrasExercicio() error {
+	caminho := os.Getenv("REGRAS_EXERCICIO_ARQUIVO")
+
+	if caminho == "" {
+		caminho = "config/regras_exercicio.json"
+	}
+
+	conteudo, err := os.ReadFile(caminho)
+
+	if err != nil {
+		return err
+	}
+
+	var novasRegras arquivoRegrasExercicio
+
+	if err := json.Unmarshal(conteudo, &novasRegras); err != nil {
+		return fmt.Errorf("%s: %w", caminho, err)
+	}
+
+	for _, regra := range novasRegras.Exercicios {
+		if regra.ExercicioFinal != 0 && regra.ExercicioFinal < regra.ExercicioInicial {
+			return fmt.Errorf("%s: o exercício final %d é anterior ao inicial %d", caminho, regra.ExercicioFinal, regra.ExercicioInicial)
+		}
+
+		for nome, demonstrativo := range regra.Demonstrativos {
+			if err := validarDemonstrativo(demonstrativo); err != nil {
+				return fmt.Errorf("%s: demonstrativo %s do exercício %d: %w", caminho, nome, regra.ExercicioInicial, err)
+			}
+		}
+	}
+
+	regrasMutex.Lock()
+	regras = novasRegras
+	regrasMutex.Unlock()
+
+	log.Printf("CarregarRegrasExercicio: versão %s carregada de %s", novasRegras.Versao, caminho)
+
+	return nil
+}
+
+// RegrasDoExercicio retorna as regras vigentes no exercício. Quando mais de uma
+// faixa de exercícios cobre o ano, prevalece a última do arquivo.
+func RegrasDoExercicio(anoExercicio int) (regrasExercicio, bool) {
+	regrasMutex.RLock()
+	defer regrasMutex.RUnlock()
+
+	for i := len(regras.Exercicios) - 1; i >= 0; i-- {
+		regra := regras.Exercicios[i]
+
+		if anoExercicio >= regra.ExercicioInicial && (regra.ExercicioFinal == 0 || anoExercicio <= regra.ExercicioFinal) {
+			return regra, true
+		}
+	}
+
+	return regrasExercicio{}, false
+}
+
+// RegraInformacoesComplementaresMSC retorna a regra de informações
+// complementares da conta SICONFI, ou falso quando nenhuma regra a cobre.
+func (r regrasExercicio) RegraInformacoesComplementaresMSC(codigoContaSICONFI string) (regraInformacoesComplementaresMSC, bool) {
+	var regraConta regraInformacoesComplementaresMSC
+	encontrada := false
+
+	for _, regra := range r.InformacoesComplementaresMSC {
+		if strings.HasPrefix(codigoContaSICONFI, regra.PrefixoConta) && (!encontrada || len(regra.PrefixoConta) > len(regraConta.PrefixoConta)) {
+			regraConta = regra
+			encontrada = true
+		}
+	}
+
+	return regraConta, encontrada
+}
+
+// VersaoRegrasExercicio retorna a versão do arquivo de regras carregado.
+func VersaoRegrasExercicio() string {
+	regrasMutex.RLock()
+	defer regrasMutex.RUnlock()
+
+	return regras.Versao
+}
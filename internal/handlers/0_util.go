@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -12,6 +17,71 @@ var Validate = validator.New()
 
 /*** Validação ***/
 
+/*** Parâmetros ***/
+// ListaInteirosParametro lê um parâmetro de query com vários inteiros,
+// informados separados por vírgula (?x=1,2) ou com a chave repetida
+// (?x=1&x=2). Valores repetidos são ignorados.
+func ListaInteirosParametro(c echo.Context, nome string) ([]int, error) {
+	var valores []int
+
+	for _, parametro := range c.QueryParams()[nome] {
+		for _, texto := range strings.Split(parametro, ",") {
+			texto = strings.TrimSpace(texto)
+
+			if texto == "" {
+				continue
+			}
+
+			valor, err := strconv.Atoi(texto)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if !slices.Contains(valores, valor) {
+				valores = append(valores, valor)
+			}
+		}
+	}
+
+	return valores, nil
+}
+
+// ParametrosNaoSuportados retorna uma mensagem de erro para cada parâmetro de
+// query informado que o endpoint não aceita.
+func ParametrosNaoSuportados(c echo.Context, nomes ...string) []string {
+	var erros []string
+
+	for _, nome := range nomes {
+		if _, ok := c.QueryParams()[nome]; ok {
+			erros = append(erros, fmt.Sprintf("O parâmetro '%s' não é suportado por este relatório.", nome))
+		}
+	}
+
+	return erros
+}
+
+/*** Parâmetros ***/
+
+/*** Funções de Template ***/
+var FuncoesTemplate template.FuncMap = template.FuncMap{
+	"dominio": IDItemDominioSimbolico,
+	"lista":   ListaSQL,
+}
+
+// ListaSQL formata os códigos para uso em uma cláusula IN, como em "15301, 15601".
+func ListaSQL(codigos []int) string {
+	textos := make([]string, len(codigos))
+
+	for i, codigo := range codigos {
+		textos[i] = strconv.Itoa(codigo)
+	}
+
+	return strings.Join(textos, ", ")
+}
+
+/*** Funções de Template ***/
+
 /*** Erro ***/
 type Erro echo.HTTPError // @name Erro
 
@@ -20,6 +90,7 @@ var ErroExecucaoTemplate *echo.HTTPError = echo.NewHTTPError(http.StatusInternal
 var ErroConsultaBancoDados *echo.HTTPError = echo.NewHTTPError(http.StatusInternalServerError, "Ocorreu um erro ao consultar o banco de dados.")
 var ErroConsultaLinhaBancoDados *echo.HTTPError = echo.NewHTTPError(http.StatusInternalServerError, "Ocorreu um erro ao consultar uma linha no banco de dados.")
 var ErroRedeOuResultadoBancoDados *echo.HTTPError = echo.NewHTTPError(http.StatusInternalServerError, "Ocorreu um erro de rede ou problema no resultado do banco de dados.")
+var ErroGeracaoArquivo *echo.HTTPError = echo.NewHTTPError(http.StatusInternalServerError, "Ocorreu um erro ao gerar o arquivo.")
 
 func ErroValidacaoParametro(mensagem []string) *echo.HTTPError {
 	return echo.NewHTTPError(
@@ -32,6 +103,29 @@ func ErroValidacaoParametro(mensagem []string) *echo.HTTPError {
 
 /*** Erro ***/
 
+/*** Saldo ***/
+// IndicadorSaldo separa um saldo com sinal, em que o positivo é credor (como em
+// saldo atual = crédito - débito + saldo anterior), em valor absoluto e
+// indicador D (devedor) ou C (credor). O saldo zero não tem indicador.
+func IndicadorSaldo(saldo float64) (float64, string) {
+	switch {
+	case saldo > 0:
+		return saldo, "C"
+	case saldo < 0:
+		return -saldo, "D"
+	default:
+		return 0, ""
+	}
+}
+
+// SaldoInvertido indica se o saldo tem natureza contrária à natureza da conta.
+// Contas de natureza mista nunca têm saldo invertido.
+func SaldoInvertido(naturezaSaldo string, indicadorSaldo string) bool {
+	return (naturezaSaldo == "DEVEDORA" && indicadorSaldo == "C") || (naturezaSaldo == "CREDORA" && indicadorSaldo == "D")
+}
+
+/*** Saldo ***/
+
 /*** Dados Estáticos ***/
 var MesParaNome map[int]string = map[int]string{
 	1:  "JANEIRO",
@@ -48,4 +142,18 @@ var MesParaNome map[int]string = map[int]string{
 	12: "DEZEMBRO",
 }
 
+var TipoPoderParaNome map[int]string = map[int]string{
+	1: "EXECUTIVO",
+	2: "LEGISLATIVO",
+	3: "JUDICIARIO",
+	4: "MINISTERIO PUBLICO",
+}
+
+// Códigos do domínio NATUREZA_SALDO do plano de contas.
+var NaturezaSaldoParaNome map[int]string = map[int]string{
+	1: "DEVEDORA",
+	2: "CREDORA",
+	3: "MISTA",
+}
+
 /*** Dados Estáticos ***/
@@ -0,0 +1,404 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type mapeamentoContaSICONFI struct {
+	CodigoContaContabil string
+	CodigoContaSICONFI  string
+}
+
+type dadoMapeamentoContaSICONFI struct {
+	Codigo           string   `json:"codigo"`
+	Nome             string   `json:"nome,omitempty"`
+	ContasMapeadas   []string `json:"contas_mapeadas"`
+	SemMapeamento    bool     `json:"sem_mapeamento"`
+	UmParaMuitos     bool     `json:"um_para_muitos"`
+	MuitosParaUm     bool     `json:"muitos_para_um"`
+	ContaInexistente bool     `json:"conta_inexistente"`
+} // @name DadoMapeamentoContaSICONFI
+
+type relatorioMapeamentoContaSICONFI struct {
+	Direcao string
+	Dados   []dadoMapeamentoContaSICONFI
+} // @name RelatorioMapeamentoContaSICONFI
+
+// Sinalizada indica se o mapeamento da conta precisa de revisão.
+func (d dadoMapeamentoContaSICONFI) Sinalizada() bool {
+	return d.SemMapeamento || d.UmParaMuitos || d.MuitosParaUm || d.ContaInexistente
+}
+
+// ContaMapeamentoSICONFIHandler godoc
+//
+// @Summary     Mapeamento das contas contábeis do FIPLAN nas contas SICONFI
+// @Description Lista o mapeamento (ACWTB0801) das contas contábeis analíticas do FIPLAN nas contas SICONFI do exercício, a partir do plano do FIPLAN (direção fiplan) ou do SICONFI (direção siconfi), sinalizando as contas analíticas sem mapeamento, as contas do FIPLAN mapeadas em mais de uma conta SICONFI (um para muitos), as contas SICONFI que recebem mais de uma conta do FIPLAN (muitos para um) e as contas mapeadas que não são contas analíticas do plano do exercício
+// @Tags        Conta
+// @Accept      json
+// @Produce     json,text/csv
+// @Param       ano_exercicio       query    int    true  "Ano de Exercício"
+// @Param       direcao             query    string false "Direção do Mapeamento (fiplan / siconfi)" Enums(fiplan, siconfi)
+// @Param       somente_sinalizadas query    bool   false "Listar Apenas as Contas Sinalizadas?"     Enums(true, false)
+// @Param       formato             query    string false "Formato de Saída (json / csv)"            Enums(json, csv)
+// @Success     200                 {object} relatorioMapeamentoContaSICONFI
+// @Failure     400                 {object} Erro
+// @Failure     500                 {object} Erro
+// @Router      /conta/mapeamento_siconfi [get]
+func ContaMapeamentoSICONFIHandler(c echo.Context) error {
+	/*** Parâmetros ***/
+	parametros := struct {
+		AnoExercicio       int
+		Direcao            string
+		SomenteSinalizadas bool
+		Formato            string
+	}{
+		Direcao: "fiplan",
+		Formato: "json",
+	}
+	/*** Parâmetros ***/
+
+	/*** Validação dos Parâmetros ***/
+	valueBinder := echo.QueryParamsBinder(c)
+
+	var erros []string
+
+	if err := valueBinder.MustInt("ano_exercicio", &parametros.AnoExercicio).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o ano de exercício no parâmetro 'ano_exercicio'.")
+	}
+
+	if err := Validate.Var(parametros.AnoExercicio, fmt.Sprintf("gte=2010,lte=%d", time.Now().Year())); err != nil {
+		erros = append(erros, fmt.Sprintf("Por favor, forneça um ano de exercício válido entre 2010 e %d para o parâmetro 'ano_exercicio'.", time.Now().Year()))
+	}
+
+	if err := valueBinder.String("direcao", &parametros.Direcao).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça a direção do mapeamento no parâmetro 'direcao'.")
+	}
+
+	if err := Validate.Var(parametros.Direcao, "oneof=fiplan siconfi"); err != nil {
+		erros = append(erros, "Por favor, forneça uma direção de mapeamento válida (fiplan ou siconfi) para o parâmetro 'direcao'.")
+	}
+
+	if err := valueBinder.Bool("somente_sinalizadas", &parametros.SomenteSinalizadas).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça um valor válido (true ou false) para o parâmetro 'somente_sinalizadas'.")
+	}
+
+	if err := valueBinder.String("formato", &parametros.Formato).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o formato de saída no parâmetro 'formato'.")
+	}
+
+	if err := Validate.Var(parametros.Formato, "oneof=json csv"); err != nil {
+		erros = append(erros, "Por favor, forneça um formato de saída válido (json ou csv) para o parâmetro 'formato'.")
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	contasAnaliticas, erro := consultarContasContabeisAnaliticas(parametros.AnoExercicio)
+
+	if erro != nil {
+		return erro
+	}
+
+	mapeamento, erro := consultarMapeamentoContasSICONFI(parametros.AnoExercicio)
+
+	if erro != nil {
+		return erro
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	/*** Lógica Adicional ***/
+	nomesContas := map[string]string{}
+
+	for _, conta := range contasAnaliticas {
+		nomesContas[conta.Codigo] = conta.Nome
+	}
+
+	relatorio := mapearContasSICONFI(parametros.Direcao, nomesContas, mapeamento)
+
+	if parametros.SomenteSinalizadas {
+		relatorio.Dados = slices.DeleteFunc(relatorio.Dados, func(dado dadoMapeamentoContaSICONFI) bool {
+			return !dado.Sinalizada()
+		})
+	}
+
+	if parametros.Formato == "json" {
+		return c.JSON(http.StatusOK, relatorio)
+	}
+
+	conteudo, err := relatorio.CSV(nomesContas)
+
+	if err != nil {
+		log.Printf("ContaMapeamentoSICONFIHandler: %v", err)
+		return ErroGeracaoArquivo
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"mapeamento_siconfi_%d_%s.csv\"", parametros.AnoExercicio, parametros.Direcao))
+	/*** Lógica Adicional ***/
+
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", conteudo)
+}
+
+// mapearContasSICONFI monta o mapeamento na direção informada a partir das
+// contas analíticas do exercício (código e nome) e dos pares da ACWTB0801,
+// sinalizando as contas que precisam de revisão.
+func mapearContasSICONFI(direcao string, nomesContas map[string]string, mapeamento []mapeamentoContaSICONFI) relatorioMapeamentoContaSICONFI {
+	contasSICONFI := map[string][]string{}
+	contasFIPLAN := map[string][]string{}
+
+	for _, conta := range mapeamento {
+		if !slices.Contains(contasSICONFI[conta.CodigoContaContabil], conta.CodigoContaSICONFI) {
+			contasSICONFI[conta.CodigoContaContabil] = append(contasSICONFI[conta.CodigoContaContabil], conta.CodigoContaSICONFI)
+		}
+
+		if !slices.Contains(contasFIPLAN[conta.CodigoContaSICONFI], conta.CodigoContaContabil) {
+			contasFIPLAN[conta.CodigoContaSICONFI] = append(contasFIPLAN[conta.CodigoContaSICONFI], conta.CodigoContaContabil)
+		}
+	}
+
+	// Uma conta do FIPLAN é mapeada em mais de uma conta SICONFI (um para
+	// muitos) ou divide a conta SICONFI com outras contas (muitos para um).
+	umParaMuitos := func(contaContabil string) bool {
+		return len(contasSICONFI[contaContabil]) > 1
+	}
+
+	muitosParaUm := func(contaContabil string) bool {
+		for _, contaSICONFI := range contasSICONFI[contaContabil] {
+			if len(contasFIPLAN[contaSICONFI]) > 1 {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	inexistente := func(contaContabil string) bool {
+		_, ok := nomesContas[contaContabil]
+		return !ok
+	}
+
+	relatorio := relatorioMapeamentoContaSICONFI{Direcao: direcao}
+
+	if direcao == "fiplan" {
+		var codigos []string
+
+		for contaContabil := range nomesContas {
+			codigos = append(codigos, contaContabil)
+		}
+
+		// Contas mapeadas que não são contas analíticas do plano do exercício.
+		for contaContabil := range contasSICONFI {
+			if inexistente(contaContabil) {
+				codigos = append(codigos, contaContabil)
+			}
+		}
+
+		sort.Strings(codigos)
+
+		for _, contaContabil := range codigos {
+			relatorio.Dados = append(relatorio.Dados, dadoMapeamentoContaSICONFI{
+				Codigo:           contaContabil,
+				Nome:             nomesContas[contaContabil],
+				ContasMapeadas:   contasSICONFI[contaContabil],
+				SemMapeamento:    len(contasSICONFI[contaContabil]) == 0,
+				UmParaMuitos:     umParaMuitos(contaContabil),
+				MuitosParaUm:     muitosParaUm(contaContabil),
+				ContaInexistente: inexistente(contaContabil),
+			})
+		}
+	} else {
+		var codigos []string
+
+		for contaSICONFI := range contasFIPLAN {
+			codigos = append(codigos, contaSICONFI)
+		}
+
+		sort.Strings(codigos)
+
+		for _, contaSICONFI := range codigos {
+			dado := dadoMapeamentoContaSICONFI{
+				Codigo:         contaSICONFI,
+				ContasMapeadas: contasFIPLAN[contaSICONFI],
+				MuitosParaUm:   len(contasFIPLAN[contaSICONFI]) > 1,
+			}
+
+			for _, contaContabil := range contasFIPLAN[contaSICONFI] {
+				dado.UmParaMuitos = dado.UmParaMuitos || umParaMuitos(contaContabil)
+				dado.ContaInexistente = dado.ContaInexistente || inexistente(contaContabil)
+			}
+
+			relatorio.Dados = append(relatorio.Dados, dado)
+		}
+	}
+
+	return relatorio
+}
+
+// CSV gera o mapeamento separado por ponto e vírgula, com uma linha por par de
+// contas (ou pela conta sem mapeamento), para manutenção da ACWTB0801.
+func (r relatorioMapeamentoContaSICONFI) CSV(nomesContas map[string]string) ([]byte, error) {
+	var conteudo bytes.Buffer
+
+	escritor := csv.NewWriter(&conteudo)
+	escritor.Comma = ';'
+
+	sinalizacao := func(valor bool) string {
+		if valor {
+			return "SIM"
+		}
+
+		return "NAO"
+	}
+
+	cabecalho := []string{"CONTA_CONTABIL", "NOME_CONTA_CONTABIL", "CONTA_SICONFI"}
+
+	if r.Direcao == "siconfi" {
+		cabecalho = []string{"CONTA_SICONFI", "CONTA_CONTABIL", "NOME_CONTA_CONTABIL"}
+	}
+
+	cabecalho = append(cabecalho, "SEM_MAPEAMENTO", "UM_PARA_MUITOS", "MUITOS_PARA_UM", "CONTA_INEXISTENTE")
+
+	registros := [][]string{cabecalho}
+
+	for _, dado := range r.Dados {
+		sinalizacoes := []string{sinalizacao(dado.SemMapeamento), sinalizacao(dado.UmParaMuitos), sinalizacao(dado.MuitosParaUm), sinalizacao(dado.ContaInexistente)}
+
+		if len(dado.ContasMapeadas) == 0 {
+			registros = append(registros, append([]string{dado.Codigo, dado.Nome, ""}, sinalizacoes...))
+			continue
+		}
+
+		for _, contaMapeada := range dado.ContasMapeadas {
+			registro := []string{dado.Codigo, dado.Nome, contaMapeada}
+
+			if r.Direcao == "siconfi" {
+				registro = []string{dado.Codigo, contaMapeada, nomesContas[contaMapeada]}
+			}
+
+			registros = append(registros, append(registro, sinalizacoes...))
+		}
+	}
+
+	if err := escritor.WriteAll(registros); err != nil {
+		return nil, err
+	}
+
+	return conteudo.Bytes(), nil
+}
+
+// consultarContasContabeisAnaliticas consulta as contas contábeis analíticas
+// (escrituráveis) do plano de contas do exercício.
+func consultarContasContabeisAnaliticas(anoExercicio int) ([]contaContabil, *echo.HTTPError) {
+	var contasContabeis []contaContabil
+
+	queryTemplate := `SELECT
+	CONTA_CONTABIL.CODG_CONTA_CONTABIL,
+	CONTA_CONTABIL.NOME_CONTA_CONTABIL
+
+	FROM
+	ACWTB0032 CONTA_CONTABIL,
+	ITEM_DOMINIO FLAG_ESCRITURACAO
+
+	WHERE CONTA_CONTABIL.FLAG_ESCRITURACAO = FLAG_ESCRITURACAO.ID_ITEM_DOMINIO
+	AND CONTA_CONTABIL.CD_EXERCICIO = {{.}}
+	AND FLAG_ESCRITURACAO.CD_ITEM_DOMINIO = 1
+
+	ORDER BY CONTA_CONTABIL.CODG_CONTA_CONTABIL`
+
+	compactSqlQuery, erro := montarQueryFIP215("queryContasAnaliticas", queryTemplate, anoExercicio)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	log.Printf("consultarContasContabeisAnaliticas: %s", compactSqlQuery)
+	rows, err := Db.Query(compactSqlQuery)
+
+	if err != nil {
+		log.Printf("consultarContasContabeisAnaliticas: %v", err)
+		return nil, ErroConsultaBancoDados
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var conta contaContabil
+
+		if err := rows.Scan(&conta.Codigo, &conta.Nome); err != nil {
+			log.Printf("consultarContasContabeisAnaliticas: %v", err)
+			return nil, ErroConsultaLinhaBancoDados
+		}
+
+		contasContabeis = append(contasContabeis, conta)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("consultarContasContabeisAnaliticas: %v", err)
+		return nil, ErroRedeOuResultadoBancoDados
+	}
+
+	return contasContabeis, nil
+}
+
+// consultarMapeamentoContasSICONFI consulta o mapeamento das contas contábeis
+// do FIPLAN nas contas SICONFI do exercício (ACWTB0801).
+func consultarMapeamentoContasSICONFI(anoExercicio int) ([]mapeamentoContaSICONFI, *echo.HTTPError) {
+	var mapeamento []mapeamentoContaSICONFI
+
+	queryTemplate := `SELECT
+	MAPA.CODG_CONTA_CONTABIL,
+	MAPA.CODG_CONTA_SICONFI
+
+	FROM ACWTB0801 MAPA
+
+	WHERE MAPA.CD_EXERCICIO = {{.}}
+
+	ORDER BY
+	MAPA.CODG_CONTA_CONTABIL,
+	MAPA.CODG_CONTA_SICONFI`
+
+	compactSqlQuery, erro := montarQueryFIP215("queryMapeamento", queryTemplate, anoExercicio)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	log.Printf("consultarMapeamentoContasSICONFI: %s", compactSqlQuery)
+	rows, err := Db.Query(compactSqlQuery)
+
+	if err != nil {
+		log.Printf("consultarMapeamentoContasSICONFI: %v", err)
+		return nil, ErroConsultaBancoDados
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var conta mapeamentoContaSICONFI
+
+		if err := rows.Scan(&conta.CodigoContaContabil, &conta.CodigoContaSICONFI); err != nil {
+			log.Printf("consultarMapeamentoContasSICONFI: %v", err)
+			return nil, ErroConsultaLinhaBancoDados
+		}
+
+		mapeamento = append(mapeamento, conta)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("consultarMapeamentoContasSICONFI: %v", err)
+		return nil, ErroRedeOuResultadoBancoDados
+	}
+
+	return mapeamento, nil
+}
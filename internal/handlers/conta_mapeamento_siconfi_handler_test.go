@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMapearContasSICONFI(t *testing.T) {
+	nomesContas := map[string]string{
+		"111110100": "CAIXA",
+		"111110200": "BANCOS",
+		"111110300": "APLICACOES",
+		"111110400": "CONTA SEM MAPEAMENTO",
+	}
+
+	mapeamento := []mapeamentoContaSICONFI{
+		{CodigoContaContabil: "111110100", CodigoContaSICONFI: "111110101"},
+		{CodigoContaContabil: "111110100", CodigoContaSICONFI: "111110102"},
+		{CodigoContaContabil: "111110200", CodigoContaSICONFI: "111110103"},
+		{CodigoContaContabil: "111110200", CodigoContaSICONFI: "111110103"},
+		{CodigoContaContabil: "111110300", CodigoContaSICONFI: "111110103"},
+		{CodigoContaContabil: "999999999", CodigoContaSICONFI: "111110104"},
+	}
+
+	casos := []struct {
+		direcao  string
+		esperado []dadoMapeamentoContaSICONFI
+	}{
+		{
+			direcao: "fiplan",
+			esperado: []dadoMapeamentoContaSICONFI{
+				{Codigo: "111110100", Nome: "CAIXA", ContasMapeadas: []string{"111110101", "111110102"}, UmParaMuitos: true},
+				{Codigo: "111110200", Nome: "BANCOS", ContasMapeadas: []string{"111110103"}, MuitosParaUm: true},
+				{Codigo: "111110300", Nome: "APLICACOES", ContasMapeadas: []string{"111110103"}, MuitosParaUm: true},
+				{Codigo: "111110400", Nome: "CONTA SEM MAPEAMENTO", SemMapeamento: true},
+				{Codigo: "999999999", ContasMapeadas: []string{"111110104"}, ContaInexistente: true},
+			},
+		},
+		{
+			direcao: "siconfi",
+			esperado: []dadoMapeamentoContaSICONFI{
+				{Codigo: "111110101", ContasMapeadas: []string{"111110100"}, UmParaMuitos: true},
+				{Codigo: "111110102", ContasMapeadas: []string{"111110100"}, UmParaMuitos: true},
+				{Codigo: "111110103", ContasMapeadas: []string{"111110200", "111110300"}, MuitosParaUm: true},
+				{Codigo: "111110104", ContasMapeadas: []string{"999999999"}, ContaInexistente: true},
+			},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.direcao, func(t *testing.T) {
+			relatorio := mapearContasSICONFI(caso.direcao, nomesContas, mapeamento)
+
+			if relatorio.Direcao != caso.direcao {
+				t.Errorf("direção = %q, esperado %q", relatorio.Direcao, caso.direcao)
+			}
+
+			if !reflect.DeepEqual(relatorio.Dados, caso.esperado) {
+				t.Errorf("mapeamento = %+v, esperado %+v", relatorio.Dados, caso.esperado)
+			}
+		})
+	}
+}
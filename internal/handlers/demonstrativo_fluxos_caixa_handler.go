@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// DemonstrativoFluxosCaixaHandler godoc
+//
+// @Summary     Demonstração dos Fluxos de Caixa (DCASP - Anexo 18)
+// @Description Classifica a geração líquida de caixa do período do balancete (FIP215) consolidado nas atividades operacionais, de investimento e de financiamento a partir das movimentações das contas das classes 1 a 4 (método indireto), nas colunas do exercício atual e do exercício anterior (janeiro a dezembro, antes do encerramento), com o caixa e equivalentes de caixa inicial e final. Os fluxos são aproximados pelas movimentações brutas (créditos e débitos) das contas patrimoniais e de resultado, e não pelos ingressos e desembolsos da execução orçamentária; como o balancete fecha, a geração líquida é, por construção, igual à variação do caixa, e por isso não é uma conciliação independente. As linhas e as contas de cada linha são configuradas nas regras do exercício (demonstrativo demonstracao_fluxos_caixa)
+// @Tags        Demonstrativo
+// @Accept      json
+// @Produce     json,text/csv
+// @Param       ano_exercicio        query    int    true  "Ano de Exercício"
+// @Param       mes_referencia       query    int    false "Mês de Referência"                                                                            Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       ate_mes_referencia   query    bool   false "Contabilizar do Início do Exercício até o Mês de Referência?"                                 Enums(true, false)
+// @Param       mes_inicio           query    int    false "Mês de Início do Período (substitui o mês de referência)"                                     Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_fim              query    int    false "Mês de Fim do Período (substitui o mês de referência)"                                        Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_contabil         query    int    true  "Mês Contábil (1-Execução / 2-Apuração / 3-Encerramento / 4-Todos)"                            Enums(1, 2, 3, 4)
+// @Param       unidade_gestora      query    []int  false "Códigos das Unidades Gestoras (separados por vírgula ou com o parâmetro repetido)"            collectionFormat(csv)
+// @Param       unidade_orcamentaria query    []int  false "Códigos das Unidades Orçamentárias (separados por vírgula ou com o parâmetro repetido)"       collectionFormat(csv)
+// @Param       orgao                query    []int  false "Códigos dos Órgãos (separados por vírgula ou com o parâmetro repetido)"                       collectionFormat(csv)
+// @Param       tipo_poder           query    []int  false "Tipos de Poder (1-Executivo / 2-Legislativo / 3-Judiciário / 4-Ministério Público / 5-Todos)" collectionFormat(csv) Enums(1, 2, 3, 4, 5)
+// @Param       tipo_administracao   query    int    false "Tipo de Administração (1-Diretas / 2-Indiretas / 3-Todas)"                                    Enums(1, 2, 3)
+// @Param       consolidado_rpps     query    bool   false "Consolidado RPPS?"                                                                            Enums(true, false)
+// @Param       eliminar_intra       query    bool   false "Eliminar as Contas Intra-OFSS (Classes 1 a 4 com 5º Dígito Igual a 2)?"                       Enums(true, false)
+// @Param       formato              query    string false "Formato de Saída (json / csv)"                                                                Enums(json, csv)
+// @Success     200                  {object} demonstrativoContabil
+// @Failure     400                  {object} Erro
+// @Failure     500                  {object} Erro
+// @Router      /demonstrativo/fluxos_caixa [get]
+func DemonstrativoFluxosCaixaHandler(c echo.Context) error {
+	return demonstrativoContabilHandler(c, "demonstracao_fluxos_caixa")
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// DemonstrativoVariacoesPatrimoniaisHandler godoc
+//
+// @Summary     Demonstração das Variações Patrimoniais (DCASP - Anexo 15)
+// @Description Projeta o movimento das classes 3 e 4 do balancete (FIP215) consolidado do período na Demonstração das Variações Patrimoniais, com os grupos das variações patrimoniais aumentativas e diminutivas e o resultado patrimonial do período, nas colunas do exercício atual e do exercício anterior (janeiro a dezembro, antes da apuração). As linhas e as contas de cada linha são configuradas nas regras do exercício (demonstrativo demonstracao_variacoes_patrimoniais). Use o mês contábil 1 (execução), já que a apuração do resultado zera as contas de variação patrimonial
+// @Tags        Demonstrativo
+// @Accept      json
+// @Produce     json,text/csv
+// @Param       ano_exercicio        query    int    true  "Ano de Exercício"
+// @Param       mes_referencia       query    int    false "Mês de Referência"                                                                            Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       ate_mes_referencia   query    bool   false "Contabilizar do Início do Exercício até o Mês de Referência?"                                 Enums(true, false)
+// @Param       mes_inicio           query    int    false "Mês de Início do Período (substitui o mês de referência)"                                     Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_fim              query    int    false "Mês de Fim do Período (substitui o mês de referência)"                                        Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_contabil         query    int    true  "Mês Contábil (1-Execução / 2-Apuração / 3-Encerramento / 4-Todos)"                            Enums(1, 2, 3, 4)
+// @Param       unidade_gestora      query    []int  false "Códigos das Unidades Gestoras (separados por vírgula ou com o parâmetro repetido)"            collectionFormat(csv)
+// @Param       unidade_orcamentaria query    []int  false "Códigos das Unidades Orçamentárias (separados por vírgula ou com o parâmetro repetido)"       collectionFormat(csv)
+// @Param       orgao                query    []int  false "Códigos dos Órgãos (separados por vírgula ou com o parâmetro repetido)"                       collectionFormat(csv)
+// @Param       tipo_poder           query    []int  false "Tipos de Poder (1-Executivo / 2-Legislativo / 3-Judiciário / 4-Ministério Público / 5-Todos)" collectionFormat(csv) Enums(1, 2, 3, 4, 5)
+// @Param       tipo_administracao   query    int    false "Tipo de Administração (1-Diretas / 2-Indiretas / 3-Todas)"                                    Enums(1, 2, 3)
+// @Param       consolidado_rpps     query    bool   false "Consolidado RPPS?"                                                                            Enums(true, false)
+// @Param       eliminar_intra       query    bool   false "Eliminar as Contas Intra-OFSS (Classes 1 a 4 com 5º Dígito Igual a 2)?"                       Enums(true, false)
+// @Param       formato              query    string false "Formato de Saída (json / csv)"                                                                Enums(json, csv)
+// @Success     200                  {object} demonstrativoContabil
+// @Failure     400                  {object} Erro
+// @Failure     500                  {object} Erro
+// @Router      /demonstrativo/variacoes_patrimoniais [get]
+func DemonstrativoVariacoesPatrimoniaisHandler(c echo.Context) error {
+	return demonstrativoContabilHandler(c, "demonstracao_variacoes_patrimoniais")
+}
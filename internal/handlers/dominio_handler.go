@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type relatorioDominio struct {
+	Nome  string
+	Dados []itemDominio
+} // @name RelatorioDominio
+
+// DominioHandler godoc
+//
+// @Summary     Itens de domínio
+// @Description Lista os itens de um domínio do FIPLAN (tabela ITEM_DOMINIO), com o ID usado nesta instalação
+// @Tags        Domínio
+// @Accept      json
+// @Produce     json
+// @Param       nome       path     string true  "Nome do Domínio"
+// @Success     200        {object} relatorioDominio
+// @Failure     400        {object} Erro
+// @Failure     404        {object} Erro
+// @Failure     500        {object} Erro
+// @Router      /dominio/{nome} [get]
+func DominioHandler(c echo.Context) error {
+	/*** Parâmetros ***/
+	parametros := struct {
+		Nome string
+	}{}
+	/*** Parâmetros ***/
+
+	/*** Validação dos Parâmetros ***/
+	var erros []string
+
+	parametros.Nome = c.Param("nome")
+
+	if err := Validate.Var(parametros.Nome, "required,max=100"); err != nil {
+		erros = append(erros, "Por favor, forneça um nome de domínio válido no caminho '/dominio/{nome}'.")
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	itens, err := ItensDominio(parametros.Nome)
+
+	if errors.Is(err, errDominioInexistente) {
+		return echo.NewHTTPError(http.StatusNotFound, "O domínio informado não foi encontrado.")
+	}
+
+	if err != nil {
+		log.Printf("DominioHandler: %v", err)
+		return ErroConsultaBancoDados
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	return c.JSON(http.StatusOK, relatorioDominio{Nome: parametros.Nome, Dados: itens})
+}
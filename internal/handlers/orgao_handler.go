@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type orgao struct {
+	Codigo        string `json:"codigo"`
+	Nome          string `json:"nome"`
+	TipoPoder     int    `json:"tipo_poder"`
+	NomeTipoPoder string `json:"nome_tipo_poder"`
+} // @name Orgao
+
+type relatorioOrgaos struct {
+	Dados []orgao
+} // @name RelatorioOrgaos
+
+// OrgaoHandler godoc
+//
+// @Summary     Órgãos
+// @Description Lista os órgãos do exercício, com o seu tipo de poder
+// @Tags        Unidade
+// @Accept      json
+// @Produce     json
+// @Param       ano_exercicio query    int true  "Ano de Exercício"
+// @Param       tipo_poder    query    int false "Tipo de Poder (1-Executivo / 2-Legislativo / 3-Judiciário / 4-Ministério Público)" Enums(1, 2, 3, 4)
+// @Success     200           {object} relatorioOrgaos
+// @Failure     400           {object} Erro
+// @Failure     500           {object} Erro
+// @Router      /orgao [get]
+func OrgaoHandler(c echo.Context) error {
+	/*** Parâmetros ***/
+	parametros := struct {
+		AnoExercicio int
+		TipoPoder    int
+	}{}
+	/*** Parâmetros ***/
+
+	/*** Validação dos Parâmetros ***/
+	valueBinder := echo.QueryParamsBinder(c)
+
+	var erros []string
+
+	if err := valueBinder.MustInt("ano_exercicio", &parametros.AnoExercicio).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o ano de exercício no parâmetro 'ano_exercicio'.")
+	}
+
+	if err := Validate.Var(parametros.AnoExercicio, fmt.Sprintf("gte=2010,lte=%d", time.Now().Year())); err != nil {
+		erros = append(erros, fmt.Sprintf("Por favor, forneça um ano de exercício válido entre 2010 e %d para o parâmetro 'ano_exercicio'.", time.Now().Year()))
+	}
+
+	if err := valueBinder.Int("tipo_poder", &parametros.TipoPoder).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o tipo de poder no parâmetro 'tipo_poder'.")
+	}
+
+	if err := Validate.Var(parametros.TipoPoder, "gte=0,lte=4"); err != nil {
+		erros = append(erros, "Por favor, forneça um tipo de poder válido entre 1 e 4 para o parâmetro 'tipo_poder'.")
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	var sqlQuery strings.Builder
+
+	var orgaos relatorioOrgaos
+
+	queryTemplate := `SELECT
+	                  ORG.CD_ORGAO,
+	                  ORG.DS_ORGAO,
+	                  NVL(ORG.FLG_TP_PODER, 0)
+
+	                  FROM ORGAO ORG
+
+	                  WHERE ORG.CD_EXERCICIO = {{.AnoExercicio}}
+
+	                  {{if .TipoPoder}}
+	                  AND ORG.FLG_TP_PODER = {{.TipoPoder}}
+	                  {{end}}
+
+	                  ORDER BY ORG.CD_ORGAO ASC`
+
+	tmpl, err := template.New("queryOrgaos").Parse(queryTemplate)
+
+	if err != nil {
+		log.Printf("OrgaoHandler: %v", err)
+		return ErroMontagemTemplate
+	}
+
+	err = tmpl.Execute(&sqlQuery, parametros)
+
+	if err != nil {
+		log.Printf("OrgaoHandler: %v", err)
+		return ErroExecucaoTemplate
+	}
+
+	compactSqlQuery := strings.Join(strings.Fields(sqlQuery.String()), " ")
+	log.Printf("OrgaoHandler: %s", compactSqlQuery)
+	rows, err := Db.Query(compactSqlQuery)
+
+	sqlQuery.Reset()
+
+	if err != nil {
+		log.Printf("OrgaoHandler: %v", err)
+		return ErroConsultaBancoDados
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var dado orgao
+
+		if err := rows.Scan(&dado.Codigo, &dado.Nome, &dado.TipoPoder); err != nil {
+			log.Printf("OrgaoHandler: %v", err)
+			return ErroConsultaLinhaBancoDados
+		}
+
+		dado.NomeTipoPoder = TipoPoderParaNome[dado.TipoPoder]
+
+		orgaos.Dados = append(orgaos.Dados, dado)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("OrgaoHandler: %v", err)
+		return ErroRedeOuResultadoBancoDados
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	return c.JSON(http.StatusOK, orgaos)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type poderOrgaoSICONFI struct {
+	Codigo int    `json:"codigo"`
+	Nome   string `json:"nome"`
+} // @name PoderOrgaoSICONFI
+
+type relatorioPoderesOrgaosSICONFI struct {
+	Dados []poderOrgaoSICONFI
+} // @name RelatorioPoderesOrgaosSICONFI
+
+// PoderOrgaoSICONFIHandler godoc
+//
+// @Summary     Poderes/órgãos SICONFI
+// @Description Lista os poderes/órgãos SICONFI do exercício, usados no parâmetro 'codigo_poder_orgao' da MSC (FIP215M)
+// @Tags        Unidade
+// @Accept      json
+// @Produce     json
+// @Param       ano_exercicio query    int true "Ano de Exercício"
+// @Success     200           {object} relatorioPoderesOrgaosSICONFI
+// @Failure     400           {object} Erro
+// @Failure     500           {object} Erro
+// @Router      /poder_orgao_siconfi [get]
+func PoderOrgaoSICONFIHandler(c echo.Context) error {
+	/*** Parâmetros ***/
+	parametros := struct {
+		AnoExercicio int
+	}{}
+	/*** Parâmetros ***/
+
+	/*** Validação dos Parâmetros ***/
+	valueBinder := echo.QueryParamsBinder(c)
+
+	var erros []string
+
+	if err := valueBinder.MustInt("ano_exercicio", &parametros.AnoExercicio).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o ano de exercício no parâmetro 'ano_exercicio'.")
+	}
+
+	if err := Validate.Var(parametros.AnoExercicio, fmt.Sprintf("gte=2010,lte=%d", time.Now().Year())); err != nil {
+		erros = append(erros, fmt.Sprintf("Por favor, forneça um ano de exercício válido entre 2010 e %d para o parâmetro 'ano_exercicio'.", time.Now().Year()))
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	var poderesOrgaos relatorioPoderesOrgaosSICONFI
+
+	var erro *echo.HTTPError
+
+	poderesOrgaos.Dados, erro = consultarPoderesOrgaosSICONFI(parametros.AnoExercicio)
+
+	if erro != nil {
+		return erro
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	return c.JSON(http.StatusOK, poderesOrgaos)
+}
+
+// consultarPoderesOrgaosSICONFI consulta os poderes/órgãos SICONFI do exercício
+// (ACWTB0803).
+func consultarPoderesOrgaosSICONFI(anoExercicio int) ([]poderOrgaoSICONFI, *echo.HTTPError) {
+	var sqlQuery strings.Builder
+
+	var poderesOrgaos []poderOrgaoSICONFI
+
+	queryTemplate := `SELECT UNIQUE CODG_PODER_ORGAO_SICONFI, NOME_PODER_ORGAO_SICONFI
+
+	                  FROM ACWTB0803
+
+	                  WHERE CD_EXERCICIO = {{.}}
+
+	                  ORDER BY CODG_PODER_ORGAO_SICONFI ASC`
+
+	tmpl, err := template.New("queryPoderesOrgaosSICONFI").Parse(queryTemplate)
+
+	if err != nil {
+		log.Printf("consultarPoderesOrgaosSICONFI: %v", err)
+		return nil, ErroMontagemTemplate
+	}
+
+	err = tmpl.Execute(&sqlQuery, anoExercicio)
+
+	if err != nil {
+		log.Printf("consultarPoderesOrgaosSICONFI: %v", err)
+		return nil, ErroExecucaoTemplate
+	}
+
+	compactSqlQuery := strings.Join(strings.Fields(sqlQuery.String()), " ")
+	log.Printf("consultarPoderesOrgaosSICONFI: %s", compactSqlQuery)
+	rows, err := Db.Query(compactSqlQuery)
+
+	if err != nil {
+		log.Printf("consultarPoderesOrgaosSICONFI: %v", err)
+		return nil, ErroConsultaBancoDados
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var dado poderOrgaoSICONFI
+
+		if err := rows.Scan(&dado.Codigo, &dado.Nome); err != nil {
+			log.Printf("consultarPoderesOrgaosSICONFI: %v", err)
+			return nil, ErroConsultaLinhaBancoDados
+		}
+
+		poderesOrgaos = append(poderesOrgaos, dado)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("consultarPoderesOrgaosSICONFI: %v", err)
+		return nil, ErroRedeOuResultadoBancoDados
+	}
+
+	return poderesOrgaos, nil
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type relatorioRegrasExercicio struct {
+	Versao       string
+	AnoExercicio int
+	Dados        regrasExercicio
+} // @name RelatorioRegrasExercicio
+
+// RegrasExercicioHandler godoc
+//
+// @Summary     Regras de negócio do exercício
+// @Description Fornece as regras de negócio aplicadas aos relatórios no exercício, como as unidades orçamentárias do RPPS
+// @Tags        Configuração
+// @Accept      json
+// @Produce     json
+// @Param       ano_exercicio query    int true "Ano de Exercício"
+// @Success     200           {object} relatorioRegrasExercicio
+// @Failure     400           {object} Erro
+// @Failure     404           {object} Erro
+// @Router      /regras_exercicio [get]
+func RegrasExercicioHandler(c echo.Context) error {
+	/*** Parâmetros ***/
+	parametros := struct {
+		AnoExercicio int
+	}{}
+	/*** Parâmetros ***/
+
+	/*** Validação dos Parâmetros ***/
+	valueBinder := echo.QueryParamsBinder(c)
+
+	var erros []string
+
+	if err := valueBinder.MustInt("ano_exercicio", &parametros.AnoExercicio).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o ano de exercício no parâmetro 'ano_exercicio'.")
+	}
+
+	if err := Validate.Var(parametros.AnoExercicio, fmt.Sprintf("gte=2010,lte=%d", time.Now().Year())); err != nil {
+		erros = append(erros, fmt.Sprintf("Por favor, forneça um ano de exercício válido entre 2010 e %d para o parâmetro 'ano_exercicio'.", time.Now().Year()))
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+	/*** Validação dos Parâmetros ***/
+
+	regras, ok := RegrasDoExercicio(parametros.AnoExercicio)
+
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Não há regras de negócio configuradas para o exercício %d.", parametros.AnoExercicio))
+	}
+
+	return c.JSON(http.StatusOK, relatorioRegrasExercicio{
+		Versao:       VersaoRegrasExercicio(),
+		AnoExercicio: parametros.AnoExercicio,
+		Dados:        regras,
+	})
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type valorExercicioComparativoFIP215 struct {
+	AnoExercicio                 int      `json:"ano_exercicio"`
+	ContaExistente               bool     `json:"conta_existente"`
+	SaldoAnterior                float64  `json:"saldo_anterior"`
+	ValorCredito                 float64  `json:"valor_credito"`
+	ValorDebito                  float64  `json:"valor_debito"`
+	SaldoAtual                   float64  `json:"saldo_atual"`
+	VariacaoSaldoAtual           float64  `json:"variacao_saldo_atual"`
+	VariacaoPercentualSaldoAtual *float64 `json:"variacao_percentual_saldo_atual"`
+} // @name ValorExercicioComparativoFIP215
+
+type dadoComparativoFIP215 struct {
+	CodigoContaContabil string                            `json:"codigo_conta_contabil"`
+	NomeContaContabil   string                            `json:"nome_conta_contabil"`
+	Exercicios          []valorExercicioComparativoFIP215 `json:"exercicios"`
+} // @name DadoComparativoFIP215
+
+type relatorioComparativoFIP215 struct {
+	AnosExercicio []int
+	Dados         []dadoComparativoFIP215
+} // @name RelatorioComparativoFIP215
+
+// RelatorioFIP215ComparativoHandler godoc
+//
+// @Summary     FIP215 - Balancete de Verificação Comparativo entre Exercícios
+// @Description Executa o balancete com os mesmos filtros no exercício base e nos exercícios de comparação, alinha as contas pelo código e calcula a variação do saldo atual em relação ao exercício base
+// @Tags        Relatório
+// @Accept      json
+// @Produce     json
+// @Param       ano_exercicio                    query    int    true  "Ano de Exercício Base"
+// @Param       anos_comparacao                  query    []int  true  "Anos de Exercício para Comparação (separados por vírgula ou com o parâmetro repetido)"          collectionFormat(csv)
+// @Param       unidade_gestora                  query    []int  false "Códigos das Unidades Gestoras (separados por vírgula ou com o parâmetro repetido)"              collectionFormat(csv)
+// @Param       unidade_orcamentaria             query    []int  false "Códigos das Unidades Orçamentárias (separados por vírgula ou com o parâmetro repetido)"         collectionFormat(csv)
+// @Param       orgao                            query    []int  false "Códigos dos Órgãos (separados por vírgula ou com o parâmetro repetido)"                         collectionFormat(csv)
+// @Param       mes_referencia                   query    int    false "Mês de Referência"                                                                              Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       ate_mes_referencia               query    bool   false "Contabilizar do Início do Exercício até o Mês de Referência?"                                   Enums(true, false)
+// @Param       mes_inicio                       query    int    false "Mês de Início do Período (substitui o mês de referência)"                                       Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_fim                          query    int    false "Mês de Fim do Período (substitui o mês de referência)"                                          Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_contabil                     query    int    true  "Mês Contábil (1-Execução / 2-Apuração / 3-Encerramento / 4-Todos)"                              Enums(1, 2, 3, 4)
+// @Param       tipo_poder                       query    []int  false "Tipos de Poder (1-Executivo / 2-Legislativo / 3-Judiciário / 4-Ministério Público / 5-Todos)"   collectionFormat(csv) Enums(1, 2, 3, 4, 5)
+// @Param       tipo_administracao               query    int    false "Tipo de Administração (1-Diretas / 2-Indiretas / 3-Todas)"                                      Enums(1, 2, 3)
+// @Param       tipo_encerramento                query    int    false "Tipo de Encerramento (1-Encerra ao Final do Exercício / 2-Transfere para o Exercício Seguinte)" Enums(1, 2)
+// @Param       consolidado_rpps                 query    bool   false "Consolidado RPPS?"                                                                              Enums(true, false)
+// @Param       indicativo_conta_contabil_rp     query    int    false "Indicativo de Conta Contábil de RP"                                                             Enums(1, 2)
+// @Param       indicativo_superavit_fincanceiro query    int    false "Indicativo de Superávit Financeiro"                                                             Enums(1, 2)
+// @Param       indicativo_composicao_msc        query    int    false "Indicativo de Composição da MSC (1-Sim / 2-Não)"                                                Enums(1, 2)
+// @Param       nivel_maximo                     query    int    false "Nível Máximo das Contas (1-Classe / 2-Grupo / 3-Subgrupo / ...)"                                Enums(1, 2, 3, 4, 5, 6, 7, 8)
+// @Param       conta_inicio                     query    string false "Código da Conta Contábil Inicial (sem pontos, completado com zeros)"
+// @Param       conta_fim                        query    string false "Código da Conta Contábil Final (sem pontos, completado com noves)"
+// @Param       prefixo_conta                    query    string false "Prefixo do Código da Conta Contábil (sem pontos)"
+// @Success     200                              {object} relatorioComparativoFIP215
+// @Failure     400                              {object} Erro
+// @Failure     500                              {object} Erro
+// @Router      /relatorio/fip_215/comparativo [get]
+func RelatorioFIP215ComparativoHandler(c echo.Context) error {
+	/*** Parâmetros ***/
+	parametros := struct {
+		Base           parametrosRelatorioFIP215
+		AnosComparacao []int
+	}{}
+	/*** Parâmetros ***/
+
+	/*** Validação dos Parâmetros ***/
+	var erros []string
+
+	parametros.Base, erros = lerParametrosRelatorioFIP215(c, true)
+
+	anosComparacao, err := ListaInteirosParametro(c, "anos_comparacao")
+
+	if err != nil || len(anosComparacao) == 0 {
+		erros = append(erros, "Por favor, forneça os anos de exercício para comparação no parâmetro 'anos_comparacao'.")
+	}
+
+	for _, anoExercicio := range anosComparacao {
+		if err := Validate.Var(anoExercicio, fmt.Sprintf("gte=2010,lte=%d,ne=%d", time.Now().Year(), parametros.Base.AnoExercicio)); err != nil {
+			erros = append(erros, fmt.Sprintf("Por favor, forneça anos de exercício para comparação entre 2010 e %d, diferentes do ano de exercício base, no parâmetro 'anos_comparacao'.", time.Now().Year()))
+			break
+		}
+
+		_, errosRegras := regrasRelatorioFIP215(parametros.Base, anoExercicio)
+		erros = append(erros, errosRegras...)
+	}
+
+	parametros.AnosComparacao = anosComparacao
+
+	// O comparativo alinha as contas do balancete consolidado de cada
+	// exercício, então o agrupamento e as opções de apresentação não se aplicam.
+	erros = append(erros, ParametrosNaoSuportados(c, "agrupamento", "eliminar_intra", "suprimir_zerados", "somente_movimento")...)
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	// Os códigos são conferidos apenas no exercício base, já que uma unidade
+	// pode deixar de existir nos demais exercícios da comparação.
+	erros, erro := codigosInexistentesRelatorioFIP215(parametros.Base)
+
+	if erro != nil {
+		return erro
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	comparativo := relatorioComparativoFIP215{
+		AnosExercicio: append([]int{parametros.Base.AnoExercicio}, parametros.AnosComparacao...),
+	}
+
+	indiceContasContabeis := map[string]int{}
+
+	for e, anoExercicio := range comparativo.AnosExercicio {
+		parametrosExercicio := parametros.Base
+		parametrosExercicio.AnoExercicio = anoExercicio
+		parametrosExercicio.Regras, _ = RegrasDoExercicio(anoExercicio)
+		parametrosExercicio.Agrupamento = "consolidado"
+
+		balancete, erro := consultarRelatorioFIP215(parametrosExercicio)
+
+		if erro != nil {
+			return erro
+		}
+
+		for _, contaContabil := range balancete.Dados {
+			i, ok := indiceContasContabeis[contaContabil.CodigoContaContabil]
+
+			if !ok {
+				i = len(comparativo.Dados)
+				indiceContasContabeis[contaContabil.CodigoContaContabil] = i
+
+				dado := dadoComparativoFIP215{CodigoContaContabil: contaContabil.CodigoContaContabil}
+
+				for _, ano := range comparativo.AnosExercicio {
+					dado.Exercicios = append(dado.Exercicios, valorExercicioComparativoFIP215{AnoExercicio: ano})
+				}
+
+				comparativo.Dados = append(comparativo.Dados, dado)
+			}
+
+			// Prevalece o nome da conta no último exercício da lista em que ela aparece.
+			comparativo.Dados[i].NomeContaContabil = contaContabil.NomeContaContabil
+
+			valor := &comparativo.Dados[i].Exercicios[e]
+			valor.ContaExistente = true
+			valor.SaldoAnterior = contaContabil.SaldoAnterior
+			valor.ValorCredito = contaContabil.ValorCredito
+			valor.ValorDebito = contaContabil.ValorDebito
+			valor.SaldoAtual = contaContabil.SaldoAtual
+		}
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	/*** Lógica Adicional ***/
+	for i := range comparativo.Dados {
+		base := &comparativo.Dados[i].Exercicios[0]
+
+		for e := range comparativo.Dados[i].Exercicios {
+			valor := &comparativo.Dados[i].Exercicios[e]
+			valor.SaldoAnterior = math.Round(valor.SaldoAnterior*100.0) / 100.0
+			valor.ValorCredito = math.Round(valor.ValorCredito*100.0) / 100.0
+			valor.ValorDebito = math.Round(valor.ValorDebito*100.0) / 100.0
+			valor.SaldoAtual = math.Round(valor.SaldoAtual*100.0) / 100.0
+
+			if e == 0 {
+				continue
+			}
+
+			valor.VariacaoSaldoAtual = math.Round((valor.SaldoAtual-base.SaldoAtual)*100.0) / 100.0
+
+			if base.SaldoAtual != 0 {
+				variacaoPercentual := math.Round(valor.VariacaoSaldoAtual/math.Abs(base.SaldoAtual)*10000.0) / 100.0
+				valor.VariacaoPercentualSaldoAtual = &variacaoPercentual
+			}
+		}
+	}
+
+	sort.Slice(comparativo.Dados, func(i, j int) bool {
+		return comparativo.Dados[i].CodigoContaContabil < comparativo.Dados[j].CodigoContaContabil
+	})
+	/*** Lógica Adicional ***/
+
+	return c.JSON(http.StatusOK, comparativo)
+}
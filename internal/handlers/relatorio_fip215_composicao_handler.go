@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type dadoComposicaoFIP215 struct {
+	Codigo              string                 `json:"codigo"`
+	Nome                string                 `json:"nome"`
+	SaldoAbertura       float64                `json:"saldo_abertura"`
+	SaldoAnterior       float64                `json:"saldo_anterior"`
+	ValorCredito        float64                `json:"valor_credito"`
+	ValorDebito         float64                `json:"valor_debito"`
+	SaldoAtual          float64                `json:"saldo_atual"`
+	ValorSaldoAtual     float64                `json:"valor_saldo_atual"`
+	IndicadorSaldoAtual string                 `json:"indicador_saldo_atual"`
+	Meses               []valorMensalFIP215    `json:"meses"`
+	UnidadesGestoras    []dadoComposicaoFIP215 `json:"unidades_gestoras,omitempty"`
+} // @name DadoComposicaoFIP215
+
+type relatorioComposicaoFIP215 struct {
+	CodigoContaContabil   string
+	NomeContaContabil     string
+	Total                 dadoComposicaoFIP215
+	UnidadesOrcamentarias []dadoComposicaoFIP215
+} // @name RelatorioComposicaoFIP215
+
+// RelatorioFIP215ComposicaoHandler godoc
+//
+// @Summary     FIP215 - Composição de uma Conta do Balancete por Unidade
+// @Description Detalha o valor de uma conta contábil do balancete, sintética ou analítica, por unidade orçamentária e unidade gestora, com o saldo de abertura do exercício e o movimento de cada mês do período
+// @Tags        Relatório
+// @Accept      json
+// @Produce     json
+// @Param       ano_exercicio                    query    int    true  "Ano de Exercício"
+// @Param       conta_contabil                   query    string true  "Código Completo da Conta Contábil (com ou sem pontos)"
+// @Param       unidade_gestora                  query    []int  false "Códigos das Unidades Gestoras (separados por vírgula ou com o parâmetro repetido)"              collectionFormat(csv)
+// @Param       unidade_orcamentaria             query    []int  false "Códigos das Unidades Orçamentárias (separados por vírgula ou com o parâmetro repetido)"         collectionFormat(csv)
+// @Param       orgao                            query    []int  false "Códigos dos Órgãos (separados por vírgula ou com o parâmetro repetido)"                         collectionFormat(csv)
+// @Param       mes_referencia                   query    int    false "Mês de Referência"                                                                              Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       ate_mes_referencia               query    bool   false "Contabilizar do Início do Exercício até o Mês de Referência?"                                   Enums(true, false)
+// @Param       mes_inicio                       query    int    false "Mês de Início do Período (substitui o mês de referência)"                                       Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_fim                          query    int    false "Mês de Fim do Período (substitui o mês de referência)"                                          Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_contabil                     query    int    true  "Mês Contábil (1-Execução / 2-Apuração / 3-Encerramento / 4-Todos)"                              Enums(1, 2, 3, 4)
+// @Param       tipo_poder                       query    []int  false "Tipos de Poder (1-Executivo / 2-Legislativo / 3-Judiciário / 4-Ministério Público / 5-Todos)"   collectionFormat(csv) Enums(1, 2, 3, 4, 5)
+// @Param       tipo_administracao               query    int    false "Tipo de Administração (1-Diretas / 2-Indiretas / 3-Todas)"                                      Enums(1, 2, 3)
+// @Param       tipo_encerramento                query    int    false "Tipo de Encerramento (1-Encerra ao Final do Exercício / 2-Transfere para o Exercício Seguinte)" Enums(1, 2)
+// @Param       consolidado_rpps                 query    bool   false "Consolidado RPPS?"                                                                              Enums(true, false)
+// @Param       indicativo_conta_contabil_rp     query    int    false "Indicativo de Conta Contábil de RP"                                                             Enums(1, 2)
+// @Param       indicativo_superavit_fincanceiro query    int    false "Indicativo de Superávit Financeiro"                                                             Enums(1, 2)
+// @Param       indicativo_composicao_msc        query    int    false "Indicativo de Composição da MSC (1-Sim / 2-Não)"                                                Enums(1, 2)
+// @Success     200                              {object} relatorioComposicaoFIP215
+// @Failure     400                              {object} Erro
+// @Failure     500                              {object} Erro
+// @Router      /relatorio/fip_215/composicao [get]
+func RelatorioFIP215ComposicaoHandler(c echo.Context) error {
+	/*** Parâmetros ***/
+	parametros := struct {
+		parametrosRelatorioFIP215
+
+		// Adicionais
+		ContaContabil        string
+		PrefixoContaContabil string
+		MesesExercicio       []string
+	}{}
+	/*** Parâmetros ***/
+
+	/*** Validação dos Parâmetros ***/
+	var erros []string
+
+	parametros.parametrosRelatorioFIP215, erros = lerParametrosRelatorioFIP215(c, true)
+
+	if err := echo.QueryParamsBinder(c).MustString("conta_contabil", &parametros.ContaContabil).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o código da conta contábil no parâmetro 'conta_contabil'.")
+	}
+
+	parametros.ContaContabil = strings.ReplaceAll(parametros.ContaContabil, ".", "")
+
+	if err := Validate.Var(parametros.ContaContabil, "numeric,max=15"); err != nil {
+		erros = append(erros, "Por favor, forneça um código de conta contábil válido para o parâmetro 'conta_contabil'.")
+	}
+
+	// A composição detalha uma única conta por unidade orçamentária e unidade
+	// gestora, então o agrupamento, os filtros de contas e as opções de
+	// apresentação do balancete não se aplicam.
+	erros = append(erros, ParametrosNaoSuportados(c, "agrupamento", "nivel_maximo", "conta_inicio", "conta_fim", "prefixo_conta", "eliminar_intra", "suprimir_zerados", "somente_movimento")...)
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	erros, erro := codigosInexistentesRelatorioFIP215(parametros.parametrosRelatorioFIP215)
+
+	if erro != nil {
+		return erro
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	// Uma conta sintética é composta por todas as contas analíticas que
+	// começam com os dígitos dos seus níveis preenchidos.
+	parametros.PrefixoContaContabil, _ = prefixoContaContabil(parametros.ContaContabil)
+
+	for i := 1; i <= 12; i++ {
+		parametros.MesesExercicio = append(parametros.MesesExercicio, MesParaNome[i])
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	composicao := relatorioComposicaoFIP215{}
+
+	queryContaContabil := `SELECT
+	CODG_CONTA_CONTABIL,
+	NOME_CONTA_CONTABIL
+
+	FROM ACWTB0032
+
+	WHERE CD_EXERCICIO = {{.AnoExercicio}}
+	AND REPLACE(CODG_CONTA_CONTABIL, '.', '') = '{{.ContaContabil}}'`
+
+	compactSqlQuery, erro := montarQueryFIP215("queryContaContabil", queryContaContabil, parametros)
+
+	if erro != nil {
+		return erro
+	}
+
+	log.Printf("RelatorioFIP215ComposicaoHandler: %s", compactSqlQuery)
+	err := Db.QueryRow(compactSqlQuery).Scan(&composicao.CodigoContaContabil, &composicao.NomeContaContabil)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErroValidacaoParametro([]string{fmt.Sprintf("Não existe conta contábil com o código %s (parâmetro 'conta_contabil') no exercício %d.", parametros.ContaContabil, parametros.AnoExercicio)})
+	}
+
+	if err != nil {
+		log.Printf("RelatorioFIP215ComposicaoHandler: %v", err)
+		return ErroConsultaLinhaBancoDados
+	}
+
+	queryTemplate := `SELECT
+	COMPOSICAO.CD_UNIDADE_ORCAMENTARIA,
+	COMPOSICAO.DS_UNIDADE_ORCAMENTARIA,
+	COMPOSICAO.CD_UNIDADE_GESTORA,
+	COMPOSICAO.DS_UNIDADE_GESTORA,
+	COALESCE(SUM(SI.SALDO_ABERTURA), 0) AS SALDO_ABERTURA,
+
+	{{range $i, $mes := .MesesExercicio}}
+	{{if $i}},{{end}}
+	SUM(COMPOSICAO.VALR_CRE_{{$mes}}) AS VALR_CRE_{{$mes}},
+	SUM(COMPOSICAO.VALR_DEB_{{$mes}}) AS VALR_DEB_{{$mes}},
+	SUM(COMPOSICAO.VALR_{{$mes}}) AS VALR_{{$mes}}
+	{{end}}
+
+	FROM
+	(
+		SELECT
+		UO.CD_EXERCICIO,
+		UG.ID_UNIDADE_GESTORA,
+		TO_CHAR(UO.CD_UNIDADE_ORCAMENTARIA) AS CD_UNIDADE_ORCAMENTARIA,
+		UO.DS_UNIDADE_ORCAMENTARIA,
+		TO_CHAR(UG.CD_UNIDADE_GESTORA) AS CD_UNIDADE_GESTORA,
+		UG.DS_UNIDADE_GESTORA,
+		CC.IDEN_CONTA_CONTABIL,
+
+		{{range $i, $mes := .MesesExercicio}}
+		{{if $i}},{{end}}
+		SUM(NVL(SM.VALR_CRE_{{$mes}}, 0)) AS VALR_CRE_{{$mes}},
+		SUM(NVL(SM.VALR_DEB_{{$mes}}, 0)) AS VALR_DEB_{{$mes}},
+		SUM(NVL(SM.VALR_{{$mes}}, 0)) AS VALR_{{$mes}}
+		{{end}}
+
+		FROM
+		{{template "juncoesFIP215" .}}
+
+		{{template "filtrosFIP215" .}}
+
+		AND REPLACE(CC.CODG_CONTA_CONTABIL, '.', '') LIKE '{{.PrefixoContaContabil}}%'
+
+		GROUP BY
+		UO.CD_EXERCICIO,
+		UG.ID_UNIDADE_GESTORA,
+		UO.CD_UNIDADE_ORCAMENTARIA,
+		UO.DS_UNIDADE_ORCAMENTARIA,
+		UG.CD_UNIDADE_GESTORA,
+		UG.DS_UNIDADE_GESTORA,
+		CC.IDEN_CONTA_CONTABIL
+	) COMPOSICAO
+	LEFT JOIN
+	(
+		SELECT
+		CD_EXERCICIO,
+		ID_UNIDADE_GESTORA,
+		IDEN_CONTA_CONTABIL,
+		SUM(SALDO_ABERTURA) AS SALDO_ABERTURA
+
+		FROM ACWTB0197
+
+		GROUP BY
+		CD_EXERCICIO,
+		ID_UNIDADE_GESTORA,
+		IDEN_CONTA_CONTABIL
+	) SI ON SI.CD_EXERCICIO = COMPOSICAO.CD_EXERCICIO
+	AND SI.ID_UNIDADE_GESTORA = COMPOSICAO.ID_UNIDADE_GESTORA
+	AND SI.IDEN_CONTA_CONTABIL = COMPOSICAO.IDEN_CONTA_CONTABIL
+
+	GROUP BY
+	COMPOSICAO.CD_UNIDADE_ORCAMENTARIA,
+	COMPOSICAO.DS_UNIDADE_ORCAMENTARIA,
+	COMPOSICAO.CD_UNIDADE_GESTORA,
+	COMPOSICAO.DS_UNIDADE_GESTORA`
+
+	compactSqlQuery, erro = montarQueryFIP215("queryMain", queryTemplate, parametros)
+
+	if erro != nil {
+		return erro
+	}
+
+	log.Printf("RelatorioFIP215ComposicaoHandler: %s", compactSqlQuery)
+	rows, err := Db.Query(compactSqlQuery)
+
+	if err != nil {
+		log.Printf("RelatorioFIP215ComposicaoHandler: %v", err)
+		return ErroConsultaBancoDados
+	}
+
+	defer rows.Close()
+
+	composicao.Total = novoDadoComposicaoFIP215("", "", parametros.MesInicio, parametros.MesFim)
+	indiceUnidadesOrcamentarias := map[string]int{}
+
+	for rows.Next() {
+		var codigoUnidadeOrcamentaria, nomeUnidadeOrcamentaria, codigoUnidadeGestora, nomeUnidadeGestora string
+		var saldoAbertura float64
+
+		// Créditos, débitos e saldos acumulados de cada mês, com o índice 0
+		// reservado para o saldo antes de janeiro.
+		var creditos, debitos, saldos [13]float64
+
+		destinos := []any{
+			&codigoUnidadeOrcamentaria,
+			&nomeUnidadeOrcamentaria,
+			&codigoUnidadeGestora,
+			&nomeUnidadeGestora,
+			&saldoAbertura,
+		}
+
+		for mes := 1; mes <= 12; mes++ {
+			destinos = append(destinos, &creditos[mes], &debitos[mes], &saldos[mes])
+		}
+
+		if err := rows.Scan(destinos...); err != nil {
+			log.Printf("RelatorioFIP215ComposicaoHandler: %v", err)
+			return ErroConsultaLinhaBancoDados
+		}
+
+		unidadeGestora := dadoComposicaoUnidadeGestoraFIP215(codigoUnidadeGestora, nomeUnidadeGestora, saldoAbertura, creditos, debitos, saldos, parametros.MesInicio, parametros.MesFim)
+
+		i, ok := indiceUnidadesOrcamentarias[codigoUnidadeOrcamentaria]
+
+		if !ok {
+			i = len(composicao.UnidadesOrcamentarias)
+			indiceUnidadesOrcamentarias[codigoUnidadeOrcamentaria] = i
+			composicao.UnidadesOrcamentarias = append(composicao.UnidadesOrcamentarias, novoDadoComposicaoFIP215(codigoUnidadeOrcamentaria, nomeUnidadeOrcamentaria, parametros.MesInicio, parametros.MesFim))
+		}
+
+		somarDadoComposicaoFIP215(&composicao.UnidadesOrcamentarias[i], unidadeGestora)
+		somarDadoComposicaoFIP215(&composicao.Total, unidadeGestora)
+		composicao.UnidadesOrcamentarias[i].UnidadesGestoras = append(composicao.UnidadesOrcamentarias[i].UnidadesGestoras, unidadeGestora)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("RelatorioFIP215ComposicaoHandler: %v", err)
+		return ErroRedeOuResultadoBancoDados
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	/*** Lógica Adicional ***/
+	arredondarDadoComposicao := func(dado *dadoComposicaoFIP215) {
+		dado.SaldoAbertura = math.Round(dado.SaldoAbertura*100.0) / 100.0
+		dado.SaldoAnterior = math.Round(dado.SaldoAnterior*100.0) / 100.0
+		dado.ValorCredito = math.Round(dado.ValorCredito*100.0) / 100.0
+		dado.ValorDebito = math.Round(dado.ValorDebito*100.0) / 100.0
+		dado.SaldoAtual = math.Round(dado.SaldoAtual*100.0) / 100.0
+		dado.ValorSaldoAtual, dado.IndicadorSaldoAtual = IndicadorSaldo(dado.SaldoAtual)
+
+		for m := range dado.Meses {
+			valor := &dado.Meses[m]
+			valor.SaldoAnterior = math.Round(valor.SaldoAnterior*100.0) / 100.0
+			valor.ValorCredito = math.Round(valor.ValorCredito*100.0) / 100.0
+			valor.ValorDebito = math.Round(valor.ValorDebito*100.0) / 100.0
+			valor.SaldoAtual = math.Round(valor.SaldoAtual*100.0) / 100.0
+		}
+	}
+
+	arredondarDadoComposicao(&composicao.Total)
+
+	for i := range composicao.UnidadesOrcamentarias {
+		unidadeOrcamentaria := &composicao.UnidadesOrcamentarias[i]
+		arredondarDadoComposicao(unidadeOrcamentaria)
+
+		for j := range unidadeOrcamentaria.UnidadesGestoras {
+			arredondarDadoComposicao(&unidadeOrcamentaria.UnidadesGestoras[j])
+		}
+
+		sort.Slice(unidadeOrcamentaria.UnidadesGestoras, func(j, k int) bool {
+			return unidadeOrcamentaria.UnidadesGestoras[j].Codigo < unidadeOrcamentaria.UnidadesGestoras[k].Codigo
+		})
+	}
+
+	sort.Slice(composicao.UnidadesOrcamentarias, func(i, j int) bool {
+		return composicao.UnidadesOrcamentarias[i].Codigo < composicao.UnidadesOrcamentarias[j].Codigo
+	})
+	/*** Lógica Adicional ***/
+
+	return c.JSON(http.StatusOK, composicao)
+}
+
+// novoDadoComposicaoFIP215 retorna um valor zerado da composição, com um mês
+// para cada mês do período.
+func novoDadoComposicaoFIP215(codigo, nome string, mesInicio, mesFim int) dadoComposicaoFIP215 {
+	dado := dadoComposicaoFIP215{Codigo: codigo, Nome: nome}
+
+	for mes := mesInicio; mes <= mesFim; mes++ {
+		dado.Meses = append(dado.Meses, valorMensalFIP215{Mes: mes})
+	}
+
+	return dado
+}
+
+// dadoComposicaoUnidadeGestoraFIP215 monta o valor de uma unidade gestora no
+// período a partir do saldo de abertura e dos créditos, débitos e saldos
+// acumulados de cada mês: o saldo anterior é o do primeiro mês, e os créditos
+// e os débitos somam os de todos os meses.
+func dadoComposicaoUnidadeGestoraFIP215(codigo, nome string, saldoAbertura float64, creditos, debitos, saldos [13]float64, mesInicio, mesFim int) dadoComposicaoFIP215 {
+	dado := dadoComposicaoFIP215{
+		Codigo:        codigo,
+		Nome:          nome,
+		SaldoAbertura: saldoAbertura,
+		Meses:         valoresMensaisFIP215(saldoAbertura, creditos, debitos, saldos, mesInicio, mesFim),
+	}
+
+	for _, valor := range dado.Meses {
+		dado.ValorCredito += valor.ValorCredito
+		dado.ValorDebito += valor.ValorDebito
+	}
+
+	dado.SaldoAnterior = dado.Meses[0].SaldoAnterior
+	dado.SaldoAtual = dado.ValorCredito - dado.ValorDebito + dado.SaldoAnterior
+
+	return dado
+}
+
+// somarDadoComposicaoFIP215 soma os valores da parcela, com os mesmos meses, ao
+// valor da composição.
+func somarDadoComposicaoFIP215(dado *dadoComposicaoFIP215, parcela dadoComposicaoFIP215) {
+	dado.SaldoAbertura += parcela.SaldoAbertura
+	dado.SaldoAnterior += parcela.SaldoAnterior
+	dado.ValorCredito += parcela.ValorCredito
+	dado.ValorDebito += parcela.ValorDebito
+	dado.SaldoAtual += parcela.SaldoAtual
+
+	for m := range dado.Meses {
+		dado.Meses[m].SaldoAnterior += parcela.Meses[m].SaldoAnterior
+		dado.Meses[m].ValorCredito += parcela.Meses[m].ValorCredito
+		dado.Meses[m].ValorDebito += parcela.Meses[m].ValorDebito
+		dado.Meses[m].SaldoAtual += parcela.Meses[m].SaldoAtual
+	}
+}
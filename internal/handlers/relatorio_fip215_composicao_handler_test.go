@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDadoComposicaoUnidadeGestoraFIP215(t *testing.T) {
+	// Saldo devedor de abertura de 100, com os saldos acumulados de cada mês
+	// (débitos menos créditos desde janeiro).
+	creditos := [13]float64{0, 10, 5, 0}
+	debitos := [13]float64{0, 30, 0, 40}
+	saldos := [13]float64{0, 20, 15, 55}
+
+	casos := []struct {
+		nome      string
+		mesInicio int
+		mesFim    int
+		esperado  dadoComposicaoFIP215
+	}{
+		{
+			nome:      "um único mês",
+			mesInicio: 1,
+			mesFim:    1,
+			esperado: dadoComposicaoFIP215{
+				Codigo: "110101", Nome: "UG", SaldoAbertura: -100, SaldoAnterior: -100, ValorCredito: 10, ValorDebito: 30, SaldoAtual: -120,
+				Meses: []valorMensalFIP215{{Mes: 1, SaldoAnterior: -100, ValorCredito: 10, ValorDebito: 30, SaldoAtual: -120}},
+			},
+		},
+		{
+			nome:      "saldo anterior do primeiro mês e movimento de todos os meses",
+			mesInicio: 2,
+			mesFim:    3,
+			esperado: dadoComposicaoFIP215{
+				Codigo: "110101", Nome: "UG", SaldoAbertura: -100, SaldoAnterior: -120, ValorCredito: 5, ValorDebito: 40, SaldoAtual: -155,
+				Meses: []valorMensalFIP215{
+					{Mes: 2, SaldoAnterior: -120, ValorCredito: 5, SaldoAtual: -115},
+					{Mes: 3, SaldoAnterior: -115, ValorDebito: 40, SaldoAtual: -155},
+				},
+			},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			if dado := dadoComposicaoUnidadeGestoraFIP215("110101", "UG", -100, creditos, debitos, saldos, caso.mesInicio, caso.mesFim); !reflect.DeepEqual(dado, caso.esperado) {
+				t.Errorf("unidade gestora = %+v, esperado %+v", dado, caso.esperado)
+			}
+		})
+	}
+}
+
+func TestSomarDadoComposicaoFIP215(t *testing.T) {
+	primeira := dadoComposicaoFIP215{
+		Codigo: "110101", SaldoAbertura: -100, SaldoAnterior: -120, ValorCredito: 5, ValorDebito: 40, SaldoAtual: -155,
+		Meses: []valorMensalFIP215{
+			{Mes: 2, SaldoAnterior: -120, ValorCredito: 5, SaldoAtual: -115},
+			{Mes: 3, SaldoAnterior: -115, ValorDebito: 40, SaldoAtual: -155},
+		},
+	}
+
+	segunda := dadoComposicaoFIP215{
+		Codigo: "110102", SaldoAbertura: 30, SaldoAnterior: 30, ValorCredito: 12, SaldoAtual: 42,
+		Meses: []valorMensalFIP215{
+			{Mes: 2, SaldoAnterior: 30, ValorCredito: 2, SaldoAtual: 32},
+			{Mes: 3, SaldoAnterior: 32, ValorCredito: 10, SaldoAtual: 42},
+		},
+	}
+
+	casos := []struct {
+		nome     string
+		parcelas []dadoComposicaoFIP215
+		esperado dadoComposicaoFIP215
+	}{
+		{
+			nome: "sem parcelas",
+			esperado: dadoComposicaoFIP215{
+				Codigo: "11101", Nome: "UO",
+				Meses: []valorMensalFIP215{{Mes: 2}, {Mes: 3}},
+			},
+		},
+		{
+			nome:     "soma o total e cada mês das unidades gestoras",
+			parcelas: []dadoComposicaoFIP215{primeira, segunda},
+			esperado: dadoComposicaoFIP215{
+				Codigo: "11101", Nome: "UO", SaldoAbertura: -70, SaldoAnterior: -90, ValorCredito: 17, ValorDebito: 40, SaldoAtual: -113,
+				Meses: []valorMensalFIP215{
+					{Mes: 2, SaldoAnterior: -90, ValorCredito: 7, SaldoAtual: -83},
+					{Mes: 3, SaldoAnterior: -83, ValorCredito: 10, ValorDebito: 40, SaldoAtual: -113},
+				},
+			},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			dado := novoDadoComposicaoFIP215("11101", "UO", 2, 3)
+
+			for _, parcela := range caso.parcelas {
+				somarDadoComposicaoFIP215(&dado, parcela)
+			}
+
+			if !reflect.DeepEqual(dado, caso.esperado) {
+				t.Errorf("composição = %+v, esperado %+v", dado, caso.esperado)
+			}
+		})
+	}
+}
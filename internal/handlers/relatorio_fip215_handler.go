@@ -15,72 +15,138 @@ import (
 )
 
 type dadoRelatorioFIP215 struct {
+	CodigoAgrupamento         string  `json:"codigo_agrupamento,omitempty"`
+	NomeAgrupamento           string  `json:"nome_agrupamento,omitempty"`
 	CodigoUnidadeOrcamentaria string  `json:"codigo_unidade_orcamentaria"`
 	NomeUnidadeOrcamentaria   string  `json:"nome_unidade_orcamentaria"`
 	IDContaContabil           string  `json:"id_conta_contabil"`
 	IDContaContabilExplosao   string  `json:"id_conta_contabil_explosao"`
 	CodigoContaContabil       string  `json:"codigo_conta_contabil"`
 	NomeContaContabil         string  `json:"nome_conta_contabil"`
+	ContaEscrituravel         bool    `json:"conta_escrituravel"`
+	NaturezaSaldo             string  `json:"natureza_saldo"`
 	SaldoAnterior             float64 `json:"saldo_anterior"`
 	ValorCredito              float64 `json:"valor_credito"`
 	ValorDebito               float64 `json:"valor_debito"`
 	SaldoAtual                float64 `json:"saldo_atual"`
+	ValorSaldoAnterior        float64 `json:"valor_saldo_anterior"`
+	IndicadorSaldoAnterior    string  `json:"indicador_saldo_anterior"`
+	ValorSaldoAtual           float64 `json:"valor_saldo_atual"`
+	IndicadorSaldoAtual       string  `json:"indicador_saldo_atual"`
+	SaldoInvertido            bool    `json:"saldo_invertido"`
 } // @name DadoRelatorioFIP215
 
 type relatorioFIP215 struct {
-	Dados []dadoRelatorioFIP215
+	Agrupamento string `json:"Agrupamento,omitempty"`
+	Dados       []dadoRelatorioFIP215
+	Total       []dadoRelatorioFIP215 `json:"Total,omitempty"`
+	Eliminacoes []dadoRelatorioFIP215 `json:"Eliminacoes,omitempty"`
 } // @name RelatorioFIP215
 
+type parametrosRelatorioFIP215 struct {
+	// FIPLAN
+	AnoExercicio                  int
+	UnidadesGestoras              []int
+	UnidadesOrcamentarias         []int
+	Orgaos                        []int
+	MesReferencia                 int
+	MesInicio                     int
+	MesFim                        int
+	MesContabil                   int
+	TiposPoder                    []int
+	TipoAdministracao             int
+	TipoEncerramento              int
+	IndicativoComposicaoMSC       int
+	IndicativoContaContabilRP     int
+	IndicativoSuperavitFinanceiro int
+	ConsolidadoRPPS               bool
+	Agrupamento                   string
+	NivelMaximo                   int
+	ContaInicio                   string
+	ContaFim                      string
+	PrefixoConta                  string
+	SuprimirZerados               bool
+	SomenteMovimento              bool
+	EliminarIntra                 bool
+
+	// Adicionais
+	AteMesReferencia     bool
+	MesSaldoAnteriorNome string
+	Meses                []string
+	Regras               regrasExercicio
+}
+
 // RelatorioFIP215Handler godoc
 //
 // @Summary     FIP215 - Balancete Mensal de Verificação
-// @Description Fornece o balancete mensal de verificação
+// @Description Fornece o balancete mensal de verificação, com as contas sintéticas totalizadas em cada agrupamento e, quando agrupado, o total geral
 // @Tags        Relatório
 // @Accept      json
 // @Produce     json
-// @Param       ano_exercicio                    query    int  true  "Ano de Exercício"
-// @Param       unidade_gestora                  query    int  false "Código da Unidade Gestora"
-// @Param       unidade_orcamentaria             query    int  false "Código da Unidade Orçamentária"
-// @Param       mes_referencia                   query    int  true  "Mês de Referência"                                                                              Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
-// @Param       ate_mes_referencia               query    bool false "Contabilizar do Início do Exercício até o Mês de Referência?"                                   Enums(true, false)
-// @Param       mes_contabil                     query    int  true  "Mês Contábil (1-Execução / 2-Apuração / 3-Encerramento / 4-Todos)"                              Enums(1, 2, 3, 4)
-// @Param       tipo_poder                       query    int  false "Tipo de Poder (1-Executivo / 2-Legislativo / 3-Judiciário / 4-Ministério Público / 5-Todos)"    Enums(1, 2, 3, 4, 5)
-// @Param       tipo_administracao               query    int  false "Tipo de Administração (1-Diretas / 2-Indiretas / 3-Todas)"                                      Enums(1, 2, 3)
-// @Param       tipo_encerramento                query    int  false "Tipo de Encerramento (1-Encerra ao Final do Exercício / 2-Transfere para o Exercício Seguinte)" Enums(1, 2)
-// @Param       consolidado_rpps                 query    bool false "Consolidado RPPS?"                                                                              Enums(true, false)
-// @Param       indicativo_conta_contabil_rp     query    int  false "Indicativo de Conta Contábil de RP"                                                             Enums(1, 2)
-// @Param       indicativo_superavit_fincanceiro query    int  false "Indicativo de Superávit Financeiro"                                                             Enums(1, 2)
-// @Param       indicativo_composicao_msc        query    int  false "Indicativo de Composição da MSC (1-Sim / 2-Não)"                                                Enums(1, 2)
+// @Param       ano_exercicio                    query    int    true  "Ano de Exercício"
+// @Param       unidade_gestora                  query    []int  false "Códigos das Unidades Gestoras (separados por vírgula ou com o parâmetro repetido)"                      collectionFormat(csv)
+// @Param       unidade_orcamentaria             query    []int  false "Códigos das Unidades Orçamentárias (separados por vírgula ou com o parâmetro repetido)"                 collectionFormat(csv)
+// @Param       orgao                            query    []int  false "Códigos dos Órgãos (separados por vírgula ou com o parâmetro repetido)"                                 collectionFormat(csv)
+// @Param       mes_referencia                   query    int    false "Mês de Referência"                                                                                      Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       ate_mes_referencia               query    bool   false "Contabilizar do Início do Exercício até o Mês de Referência?"                                           Enums(true, false)
+// @Param       mes_inicio                       query    int    false "Mês de Início do Período (substitui o mês de referência)"                                               Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_fim                          query    int    false "Mês de Fim do Período (substitui o mês de referência)"                                                  Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_contabil                     query    int    true  "Mês Contábil (1-Execução / 2-Apuração / 3-Encerramento / 4-Todos)"                                      Enums(1, 2, 3, 4)
+// @Param       tipo_poder                       query    []int  false "Tipos de Poder (1-Executivo / 2-Legislativo / 3-Judiciário / 4-Ministério Público / 5-Todos)"           collectionFormat(csv) Enums(1, 2, 3, 4, 5)
+// @Param       tipo_administracao               query    int    false "Tipo de Administração (1-Diretas / 2-Indiretas / 3-Todas)"                                              Enums(1, 2, 3)
+// @Param       tipo_encerramento                query    int    false "Tipo de Encerramento (1-Encerra ao Final do Exercício / 2-Transfere para o Exercício Seguinte)"         Enums(1, 2)
+// @Param       consolidado_rpps                 query    bool   false "Consolidado RPPS?"                                                                                      Enums(true, false)
+// @Param       indicativo_conta_contabil_rp     query    int    false "Indicativo de Conta Contábil de RP"                                                                     Enums(1, 2)
+// @Param       indicativo_superavit_fincanceiro query    int    false "Indicativo de Superávit Financeiro"                                                                     Enums(1, 2)
+// @Param       indicativo_composicao_msc        query    int    false "Indicativo de Composição da MSC (1-Sim / 2-Não)"                                                        Enums(1, 2)
+// @Param       nivel_maximo                     query    int    false "Nível Máximo das Contas (1-Classe / 2-Grupo / 3-Subgrupo / ...)"                                        Enums(1, 2, 3, 4, 5, 6, 7, 8)
+// @Param       conta_inicio                     query    string false "Código da Conta Contábil Inicial (sem pontos, completado com zeros)"
+// @Param       conta_fim                        query    string false "Código da Conta Contábil Final (sem pontos, completado com noves)"
+// @Param       prefixo_conta                    query    string false "Prefixo do Código da Conta Contábil (sem pontos)"
+// @Param       suprimir_zerados                 query    bool   false "Omitir Contas sem Saldo e sem Movimento?"                                                               Enums(true, false)
+// @Param       somente_movimento                query    bool   false "Mostrar Apenas Contas com Movimento no Período?"                                                        Enums(true, false)
+// @Param       eliminar_intra                   query    bool   false "Eliminar as Contas Intra-OFSS (Classes 1 a 4 com 5º Dígito Igual a 2), listando os valores eliminados?" Enums(true, false)
+// @Param       agrupamento                      query    string false "Agrupamento das Contas (sem o parâmetro, as contas vêm por unidade orçamentária, sem total geral)"      Enums(consolidado, orgao, unidade_orcamentaria, unidade_gestora)
 // @Success     200                              {object} relatorioFIP215
 // @Failure     400                              {object} Erro
 // @Failure     500                              {object} Erro
 // @Router      /relatorio/fip_215 [get]
 func RelatorioFIP215Handler(c echo.Context) error {
-	/*** Parâmetros ***/
-	parametros := struct {
-		// FIPLAN
-		AnoExercicio                  int
-		UnidadeGestora                int
-		UnidadeOrcamentaria           int
-		MesReferencia                 int
-		MesContabil                   int
-		TipoPoder                     int
-		TipoAdministracao             int
-		TipoEncerramento              int
-		IndicativoComposicaoMSC       int
-		IndicativoContaContabilRP     int
-		IndicativoSuperavitFinanceiro int
-		ConsolidadoRPPS               bool
-
-		// Adicionais
-		AteMesReferencia          bool
-		MesReferenciaNome         string
-		MesAnteriorReferenciaNome string
-		Meses                     []string
-	}{}
-	/*** Parâmetros ***/
+	/*** Validação dos Parâmetros ***/
+	parametros, erros := lerParametrosRelatorioFIP215(c, true)
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	erros, erro := codigosInexistentesRelatorioFIP215(parametros)
 
+	if erro != nil {
+		return erro
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
 	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	contasContabeis, erro := consultarRelatorioFIP215(parametros)
+
+	if erro != nil {
+		return erro
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	return c.JSON(http.StatusOK, contasContabeis)
+}
+
+// lerParametrosRelatorioFIP215 lê e valida os parâmetros do FIP215, que também
+// são usados pelos relatórios derivados dele. Quando o período não é
+// obrigatório e não é informado, considera o exercício inteiro.
+func lerParametrosRelatorioFIP215(c echo.Context, periodoObrigatorio bool) (parametrosRelatorioFIP215, []string) {
+	var parametros parametrosRelatorioFIP215
+
 	valueBinder := echo.QueryParamsBinder(c)
 
 	var erros []string
@@ -93,15 +159,21 @@ func RelatorioFIP215Handler(c echo.Context) error {
 		erros = append(erros, fmt.Sprintf("Por favor, forneça um ano de exercício válido entre 2010 e %d para o parâmetro 'ano_exercicio'.", time.Now().Year()))
 	}
 
-	if err := valueBinder.Int("unidade_gestora", &parametros.UnidadeGestora).BindError(); err != nil {
-		erros = append(erros, "Por favor, forneça a unidade gestora no parâmetro 'unidade_gestora'.")
+	var err error
+
+	if parametros.UnidadesGestoras, err = ListaInteirosParametro(c, "unidade_gestora"); err != nil {
+		erros = append(erros, "Por favor, forneça as unidades gestoras no parâmetro 'unidade_gestora'.")
 	}
 
-	if err := valueBinder.Int("unidade_orcamentaria", &parametros.UnidadeOrcamentaria).BindError(); err != nil {
-		erros = append(erros, "Por favor, forneça a unidade orçamentária no parâmetro 'unidade_orcamentaria'.")
+	if parametros.UnidadesOrcamentarias, err = ListaInteirosParametro(c, "unidade_orcamentaria"); err != nil {
+		erros = append(erros, "Por favor, forneça as unidades orçamentárias no parâmetro 'unidade_orcamentaria'.")
 	}
 
-	if err := valueBinder.MustInt("mes_referencia", &parametros.MesReferencia).BindError(); err != nil {
+	if parametros.Orgaos, err = ListaInteirosParametro(c, "orgao"); err != nil {
+		erros = append(erros, "Por favor, forneça os órgãos no parâmetro 'orgao'.")
+	}
+
+	if err := valueBinder.Int("mes_referencia", &parametros.MesReferencia).BindError(); err != nil {
 		erros = append(erros, "Por favor, forneça o mês de referência no parâmetro 'mes_referencia'.")
 	}
 
@@ -109,17 +181,52 @@ func RelatorioFIP215Handler(c echo.Context) error {
 		erros = append(erros, "Por favor, forneça se o relatório deve ir do início do exercício até o mês de referência no parâmetro 'ate_mes_referencia'.")
 	}
 
-	if err := Validate.Var(parametros.MesReferencia, "gte=1,lte=12"); err != nil {
-		erros = append(erros, "Por favor, forneça um mês de referência válido entre 1 e 12 para o parâmetro 'mes_referencia'.")
+	if err := valueBinder.Int("mes_inicio", &parametros.MesInicio).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o mês de início no parâmetro 'mes_inicio'.")
+	}
+
+	if err := valueBinder.Int("mes_fim", &parametros.MesFim).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o mês de fim no parâmetro 'mes_fim'.")
+	}
+
+	if parametros.MesInicio != 0 || parametros.MesFim != 0 {
+		if parametros.MesReferencia != 0 {
+			erros = append(erros, "Por favor, forneça apenas o parâmetro 'mes_referencia' ou os parâmetros 'mes_inicio' e 'mes_fim'.")
+		}
+
+		if parametros.AteMesReferencia {
+			erros = append(erros, "O parâmetro 'ate_mes_referencia' não pode ser usado com os parâmetros 'mes_inicio' e 'mes_fim'.")
+		}
+	} else if !periodoObrigatorio && parametros.MesReferencia == 0 {
+		parametros.MesInicio = 1
+		parametros.MesFim = 12
+	} else if err := Validate.Var(parametros.MesReferencia, "gte=1,lte=12"); err != nil {
+		erros = append(erros, "Por favor, forneça um mês de referência válido entre 1 e 12 para o parâmetro 'mes_referencia', ou o período nos parâmetros 'mes_inicio' e 'mes_fim'.")
 	} else {
+		parametros.MesInicio = parametros.MesReferencia
+		parametros.MesFim = parametros.MesReferencia
+
 		if parametros.AteMesReferencia {
-			for i := 1; i < parametros.MesReferencia; i++ {
-				parametros.Meses = append(parametros.Meses, MesParaNome[i])
-			}
+			parametros.MesInicio = 1
 		}
+	}
 
-		parametros.MesReferenciaNome = MesParaNome[parametros.MesReferencia]
-		parametros.MesAnteriorReferenciaNome = MesParaNome[parametros.MesReferencia-1]
+	if err := Validate.Var(parametros.MesInicio, "gte=1,lte=12"); err != nil {
+		erros = append(erros, "Por favor, forneça um mês de início válido entre 1 e 12 para o parâmetro 'mes_inicio'.")
+	} else if err := Validate.Var(parametros.MesFim, fmt.Sprintf("gte=%d,lte=12", parametros.MesInicio)); err != nil {
+		erros = append(erros, fmt.Sprintf("Por favor, forneça um mês de fim válido entre %d e 12 para o parâmetro 'mes_fim'.", parametros.MesInicio))
+	} else {
+		for i := parametros.MesInicio; i <= parametros.MesFim; i++ {
+			parametros.Meses = append(parametros.Meses, MesParaNome[i])
+		}
+
+		parametros.MesSaldoAnteriorNome = MesParaNome[parametros.MesInicio-1]
+
+		// Do início do exercício até o mês de referência, o saldo anterior
+		// continua sendo o do mês anterior ao de referência.
+		if parametros.AteMesReferencia {
+			parametros.MesSaldoAnteriorNome = MesParaNome[parametros.MesReferencia-1]
+		}
 	}
 
 	if err := valueBinder.MustInt("mes_contabil", &parametros.MesContabil).BindError(); err != nil {
@@ -130,12 +237,17 @@ func RelatorioFIP215Handler(c echo.Context) error {
 		erros = append(erros, "Por favor, forneça um mês contábil válido entre 1 e 4 para o parâmetro 'mes_contabil'.")
 	}
 
-	if err := valueBinder.Int("tipo_poder", &parametros.TipoPoder).BindError(); err != nil {
-		erros = append(erros, "Por favor, forneça o tipo de poder no parâmetro 'tipo_poder'.")
+	if parametros.TiposPoder, err = ListaInteirosParametro(c, "tipo_poder"); err != nil {
+		erros = append(erros, "Por favor, forneça os tipos de poder no parâmetro 'tipo_poder'.")
 	}
 
-	if err := Validate.Var(parametros.TipoPoder, "gte=0,lte=5"); err != nil {
-		erros = append(erros, "Por favor, forneça um tipo de poder válido entre 1 e 5 para o parâmetro 'tipo_poder'.")
+	if err := Validate.Var(parametros.TiposPoder, "dive,gte=1,lte=5"); err != nil {
+		erros = append(erros, "Por favor, forneça tipos de poder válidos entre 1 e 5 para o parâmetro 'tipo_poder'.")
+	}
+
+	// O tipo de poder 5 (Todos) dispensa o filtro por poder.
+	if slices.Contains(parametros.TiposPoder, 5) {
+		parametros.TiposPoder = nil
 	}
 
 	if err := valueBinder.Int("tipo_administracao", &parametros.TipoAdministracao).BindError(); err != nil {
@@ -182,410 +294,923 @@ func RelatorioFIP215Handler(c echo.Context) error {
 		erros = append(erros, "Por favor, forneça um indicativo de composição da MSC válido entre 1 e 2 para o parâmetro 'indicativo_composicao_msc'.")
 	}
 
-	if len(erros) > 0 {
-		return ErroValidacaoParametro(erros)
+	if err := valueBinder.String("agrupamento", &parametros.Agrupamento).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o agrupamento no parâmetro 'agrupamento'.")
 	}
-	/*** Validação dos Parâmetros ***/
 
-	/*** Consulta no Banco de Dados ***/
+	if err := Validate.Var(parametros.Agrupamento, "omitempty,oneof=consolidado orgao unidade_orcamentaria unidade_gestora"); err != nil {
+		erros = append(erros, "Por favor, forneça um agrupamento válido (consolidado, orgao, unidade_orcamentaria ou unidade_gestora) para o parâmetro 'agrupamento'.")
+	}
+
+	if err := valueBinder.Int("nivel_maximo", &parametros.NivelMaximo).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o nível máximo das contas no parâmetro 'nivel_maximo'.")
+	}
+
+	if err := Validate.Var(parametros.NivelMaximo, "gte=0,lte=8"); err != nil {
+		erros = append(erros, "Por favor, forneça um nível máximo válido entre 1 e 8 para o parâmetro 'nivel_maximo'.")
+	}
+
+	if err := valueBinder.String("conta_inicio", &parametros.ContaInicio).String("conta_fim", &parametros.ContaFim).String("prefixo_conta", &parametros.PrefixoConta).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça as contas nos parâmetros 'conta_inicio', 'conta_fim' e 'prefixo_conta'.")
+	}
+
+	parametros.ContaInicio = strings.ReplaceAll(parametros.ContaInicio, ".", "")
+	parametros.ContaFim = strings.ReplaceAll(parametros.ContaFim, ".", "")
+	parametros.PrefixoConta = strings.ReplaceAll(parametros.PrefixoConta, ".", "")
+
+	if err := Validate.Var(parametros.ContaInicio, "omitempty,numeric,max=15"); err != nil {
+		erros = append(erros, "Por favor, forneça um código de conta contábil válido para o parâmetro 'conta_inicio'.")
+	}
+
+	if err := Validate.Var(parametros.ContaFim, "omitempty,numeric,max=15"); err != nil {
+		erros = append(erros, "Por favor, forneça um código de conta contábil válido para o parâmetro 'conta_fim'.")
+	}
+
+	if err := Validate.Var(parametros.PrefixoConta, "omitempty,numeric,max=15"); err != nil {
+		erros = append(erros, "Por favor, forneça um prefixo de conta contábil válido para o parâmetro 'prefixo_conta'.")
+	}
+
+	if err := valueBinder.Bool("suprimir_zerados", &parametros.SuprimirZerados).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça se as contas sem saldo e sem movimento devem ser omitidas no parâmetro 'suprimir_zerados'.")
+	}
+
+	if err := valueBinder.Bool("somente_movimento", &parametros.SomenteMovimento).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça se apenas as contas com movimento no período devem ser mostradas no parâmetro 'somente_movimento'.")
+	}
+
+	if err := valueBinder.Bool("eliminar_intra", &parametros.EliminarIntra).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça se as contas intra-OFSS devem ser eliminadas no parâmetro 'eliminar_intra'.")
+	}
+
+	if len(erros) == 0 {
+		var errosRegras []string
+		parametros.Regras, errosRegras = regrasRelatorioFIP215(parametros, parametros.AnoExercicio)
+		erros = append(erros, errosRegras...)
+	}
+
+	return parametros, erros
+}
+
+// regrasRelatorioFIP215 retorna as regras do exercício e os erros de validação
+// dos filtros que dependem delas. Só o consolidado do RPPS e os filtros do
+// Executivo e do Ministério Público usam as unidades orçamentárias
+// configuradas, então os demais relatórios não exigem regras no exercício.
+func regrasRelatorioFIP215(parametros parametrosRelatorioFIP215, anoExercicio int) (regrasExercicio, []string) {
+	var erros []string
+
+	regras, ok := RegrasDoExercicio(anoExercicio)
+
+	if !ok && (parametros.ConsolidadoRPPS || slices.Contains(parametros.TiposPoder, 1) || slices.Contains(parametros.TiposPoder, 4)) {
+		erros = append(erros, fmt.Sprintf("Não há regras de negócio configuradas para o exercício %d.", anoExercicio))
+	} else if parametros.ConsolidadoRPPS && len(regras.UnidadesOrcamentariasRPPS) == 0 {
+		erros = append(erros, fmt.Sprintf("Não há unidades orçamentárias do RPPS configuradas para o exercício %d.", anoExercicio))
+	}
+
+	return regras, erros
+}
+
+// codigosInexistentesRelatorioFIP215 confere no banco de dados se as unidades
+// gestoras, as unidades orçamentárias e os órgãos informados existem no
+// exercício, e retorna uma mensagem para cada código inexistente.
+func codigosInexistentesRelatorioFIP215(parametros parametrosRelatorioFIP215) ([]string, *echo.HTTPError) {
+	verificacoes := []struct {
+		Parametro     string
+		Descricao     string
+		Codigos       []int
+		QueryTemplate string
+	}{
+		{
+			Parametro: "unidade_gestora",
+			Descricao: "unidade gestora",
+			Codigos:   parametros.UnidadesGestoras,
+			QueryTemplate: `SELECT UG.CD_UNIDADE_GESTORA
+			                FROM UNIDADE_GESTORA UG
+			                INNER JOIN UNIDADE_ORCAMENTARIA UO ON (UO.ID_UNIDADE_ORCAMENTARIA = UG.ID_UNIDADE_ORCAMENTARIA)
+			                WHERE UO.CD_EXERCICIO = {{.AnoExercicio}}
+			                AND UG.CD_UNIDADE_GESTORA IN ({{lista .Codigos}})`,
+		},
+		{
+			Parametro: "unidade_orcamentaria",
+			Descricao: "unidade orçamentária",
+			Codigos:   parametros.UnidadesOrcamentarias,
+			QueryTemplate: `SELECT UO.CD_UNIDADE_ORCAMENTARIA
+			                FROM UNIDADE_ORCAMENTARIA UO
+			                WHERE UO.CD_EXERCICIO = {{.AnoExercicio}}
+			                AND UO.CD_UNIDADE_ORCAMENTARIA IN ({{lista .Codigos}})`,
+		},
+		{
+			Parametro: "orgao",
+			Descricao: "órgão",
+			Codigos:   parametros.Orgaos,
+			QueryTemplate: `SELECT ORG.CD_ORGAO
+			                FROM ORGAO ORG
+			                WHERE ORG.CD_EXERCICIO = {{.AnoExercicio}}
+			                AND ORG.CD_ORGAO IN ({{lista .Codigos}})`,
+		},
+	}
+
+	var erros []string
+
+	for _, verificacao := range verificacoes {
+		if len(verificacao.Codigos) == 0 {
+			continue
+		}
+
+		compactSqlQuery, erro := montarQueryFIP215("queryCodigos", verificacao.QueryTemplate, struct {
+			AnoExercicio int
+			Codigos      []int
+		}{parametros.AnoExercicio, verificacao.Codigos})
+
+		if erro != nil {
+			return nil, erro
+		}
+
+		log.Printf("codigosInexistentesRelatorioFIP215: %s", compactSqlQuery)
+		rows, err := Db.Query(compactSqlQuery)
+
+		if err != nil {
+			log.Printf("codigosInexistentesRelatorioFIP215: %v", err)
+			return nil, ErroConsultaBancoDados
+		}
+
+		var existentes []int
+
+		for rows.Next() {
+			var codigo int
+
+			if err := rows.Scan(&codigo); err != nil {
+				rows.Close()
+				log.Printf("codigosInexistentesRelatorioFIP215: %v", err)
+				return nil, ErroConsultaLinhaBancoDados
+			}
+
+			existentes = append(existentes, codigo)
+		}
+
+		err = rows.Err()
+		rows.Close()
+
+		if err != nil {
+			log.Printf("codigosInexistentesRelatorioFIP215: %v", err)
+			return nil, ErroRedeOuResultadoBancoDados
+		}
+
+		for _, codigo := range verificacao.Codigos {
+			if !slices.Contains(existentes, codigo) {
+				erros = append(erros, fmt.Sprintf("Não existe %s com o código %d (parâmetro '%s') no exercício %d.", verificacao.Descricao, codigo, verificacao.Parametro, parametros.AnoExercicio))
+			}
+		}
+	}
+
+	return erros, nil
+}
+
+/*** Queries SQL ***/
+// Junções e filtros do FIP215 sobre o saldo mensal (ACWTB0215), compartilhados
+// pelas consultas que partem dele com os apelidos UO, UG, ORG, SM e CC.
+const templateJuncoesRelatorioFIP215 = `{{define "juncoesFIP215"}}
+	UNIDADE_ORCAMENTARIA UO
+	LEFT JOIN
+	UNIDADE_GESTORA UG ON (UG.ID_UNIDADE_ORCAMENTARIA = UO.ID_UNIDADE_ORCAMENTARIA)
+	LEFT JOIN
+	ORGAO ORG ON ORG.ID_ORGAO = UO.ID_ORGAO
+	LEFT JOIN
+	ACWTB0215 SM ON (SM.ID_UNIDADE_GESTORA = UG.ID_UNIDADE_GESTORA)
+	LEFT JOIN
+	ACWTB0032 CC ON (CC.IDEN_CONTA_CONTABIL = SM.IDEN_CONTA_CONTABIL)
+	LEFT JOIN
+	ITEM_DOMINIO DOMINIO_RP ON (CC.FLAG_CONTA_CONTABIL_RP = DOMINIO_RP.ID_ITEM_DOMINIO)
+	LEFT JOIN
+	ITEM_DOMINIO DOMINIO_ENCERRA ON (CC.FLAG_TIPO_ENCERRAMENTO = DOMINIO_ENCERRA.ID_ITEM_DOMINIO)
+	LEFT JOIN
+	ITEM_DOMINIO DOMINIO_NATUREZA ON (CC.FLAG_NATUREZA_SALDO = DOMINIO_NATUREZA.ID_ITEM_DOMINIO)
+{{end}}`
+
+const templateFiltrosRelatorioFIP215 = `{{define "filtrosFIP215"}}
+	WHERE UO.CD_EXERCICIO = {{.AnoExercicio}}
+
+	{{if .UnidadesGestoras}}
+	AND UG.CD_UNIDADE_GESTORA IN ({{lista .UnidadesGestoras}})
+	{{end}}
+
+	{{if .UnidadesOrcamentarias}}
+	AND UO.CD_UNIDADE_ORCAMENTARIA IN ({{lista .UnidadesOrcamentarias}})
+	{{end}}
+
+	{{if .Orgaos}}
+	AND ORG.CD_ORGAO IN ({{lista .Orgaos}})
+	{{end}}
+
+	{{if .TipoAdministracao}}
+	AND LOWER(UO.FLG_TIPO_ADM) = '{{.TipoAdministracao}}'
+	{{end}}
+
+	{{if eq .MesContabil 1}}
+	AND SM.FLAG_MES_CONTABIL = {{dominio "MES_CONTABIL_EXECUCAO"}}
+	{{else if eq .MesContabil 2}}
+	AND SM.FLAG_MES_CONTABIL = {{dominio "MES_CONTABIL_APURACAO"}}
+	{{else if eq .MesContabil 3}}
+	AND SM.FLAG_MES_CONTABIL = {{dominio "MES_CONTABIL_ENCERRAMENTO"}}
+	{{else if eq .MesContabil 4}}
+	AND SM.FLAG_MES_CONTABIL IN ({{dominio "MES_CONTABIL_EXECUCAO"}}, {{dominio "MES_CONTABIL_APURACAO"}}, {{dominio "MES_CONTABIL_ENCERRAMENTO"}})
+	{{end}}
+
+	{{if .TiposPoder}}
+	AND (
+		{{range $i, $poder := .TiposPoder}}
+		{{if $i}}OR{{end}}
+
+		{{if eq $poder 1}}
+		(
+			ORG.FLG_TP_PODER = {{$poder}}
+			{{if $.Regras.UnidadesOrcamentariasMinisterioPublico}}
+			AND UO.CD_UNIDADE_ORCAMENTARIA NOT IN ({{lista $.Regras.UnidadesOrcamentariasMinisterioPublico}})
+			{{end}}
+		)
+		{{else if and (eq $poder 4) $.Regras.UnidadesOrcamentariasMinisterioPublico}}
+		UO.CD_UNIDADE_ORCAMENTARIA IN ({{lista $.Regras.UnidadesOrcamentariasMinisterioPublico}})
+		{{else}}
+		ORG.FLG_TP_PODER = {{$poder}}
+		{{end}}
+		{{end}}
+	)
+	{{end}}
+
+	{{if .IndicativoContaContabilRP}}
+	AND DOMINIO_RP.CD_ITEM_DOMINIO = {{.IndicativoContaContabilRP}}
+	{{end}}
+
+	{{if .TipoEncerramento}}
+	AND DOMINIO_ENCERRA.CD_ITEM_DOMINIO = {{.TipoEncerramento}}
+	{{end}}
+
+	{{if eq .IndicativoSuperavitFinanceiro 1}}
+	AND CC.FLAG_SUPERAVIT_FINANCEIRO = {{dominio "SIM"}}
+	{{else if eq .IndicativoSuperavitFinanceiro 2}}
+	AND CC.FLAG_SUPERAVIT_FINANCEIRO = {{dominio "NAO"}}
+	{{end}}
+
+	{{if eq .IndicativoComposicaoMSC 1}}
+	AND CC.FLAG_COMPOSICAO_MSC = {{dominio "SIM"}}
+	{{else if eq .IndicativoComposicaoMSC 2}}
+	AND CC.FLAG_COMPOSICAO_MSC = {{dominio "NAO"}}
+	{{end}}
+
+	{{if .ConsolidadoRPPS}}
+	AND UO.CD_UNIDADE_ORCAMENTARIA IN ({{lista .Regras.UnidadesOrcamentariasRPPS}})
+	{{end}}
+{{end}}`
+
+const queryContasContabeisSinteticasFIP215 = `SELECT
+	CONTA_CONTABIL.IDEN_CONTA_CONTABIL,
+	NVL(TO_CHAR(CONTA_CONTABIL.CONTA_EXPLOSAO), ' ') AS CONTA_EXPLOSAO,
+	CONTA_CONTABIL.CODG_CONTA_CONTABIL,
+	CONTA_CONTABIL.NOME_CONTA_CONTABIL,
+	NVL(NATUREZA_SALDO.CD_ITEM_DOMINIO, 0) AS NATUREZA_SALDO
+
+	FROM
+	ACWTB0032 CONTA_CONTABIL,
+	ACWTB0069 SUB_ITEM,
+	ACWTB0068 ITEM,
+	ACWTB0067 SUB_ELEMENTO,
+	ACWTB0066 ELEMENTO,
+	ACWTB0065 SUB_GRUPO,
+	ACWTB0064 GRUPO,
+	ACWTB0063 CLASSE,
+	ITEM_DOMINIO FLAG_ESCRITURACAO,
+	ITEM_DOMINIO NATUREZA_SALDO
+
+	WHERE CONTA_CONTABIL.IDEN_SUBITEM = SUB_ITEM.IDEN_SUBITEM
+	AND SUB_ITEM.IDEN_ITEM = ITEM.IDEN_ITEM
+	AND ITEM.IDEN_SUBELEMENTO = SUB_ELEMENTO.IDEN_SUBELEMENTO
+	AND SUB_ELEMENTO.IDEN_ELEMENTO = ELEMENTO.IDEN_ELEMENTO
+	AND ELEMENTO.IDEN_SUBGRUPO = SUB_GRUPO.IDEN_SUBGRUPO
+	AND SUB_GRUPO.IDEN_GRUPO = GRUPO.IDEN_GRUPO
+	AND GRUPO.IDEN_CLASSE = CLASSE.IDEN_CLASSE
+	AND CONTA_CONTABIL.FLAG_ESCRITURACAO = FLAG_ESCRITURACAO.ID_ITEM_DOMINIO
+	AND CONTA_CONTABIL.FLAG_NATUREZA_SALDO = NATUREZA_SALDO.ID_ITEM_DOMINIO(+)
+	AND CLASSE.CD_EXERCICIO = {{.}}
+	AND FLAG_ESCRITURACAO.CD_ITEM_DOMINIO = 2`
+
+const queryContasContabeisEspecificasFIP215 = `SELECT
+	RESULTADO_SALDO_INICIAL.CD_AGRUPAMENTO,
+	RESULTADO_SALDO_INICIAL.DS_AGRUPAMENTO,
+	RESULTADO_SALDO_INICIAL.CD_UNIDADE_ORCAMENTARIA,
+	RESULTADO_SALDO_INICIAL.DS_UNIDADE_ORCAMENTARIA,
+	RESULTADO_SALDO_INICIAL.IDEN_CONTA_CONTABIL,
+	RESULTADO_SALDO_INICIAL.CONTA_EXPLOSAO,
+	RESULTADO_SALDO_INICIAL.CODG_CONTA_CONTABIL,
+	RESULTADO_SALDO_INICIAL.NOME_CONTA_CONTABIL,
+	RESULTADO_SALDO_INICIAL.NATUREZA_SALDO,
+	SUM(RESULTADO_SALDO_INICIAL.SALDO_ANTERIOR) AS SALDO_ANTERIOR,
+	SUM(RESULTADO_SALDO_INICIAL.VALOR_CREDITO) AS VALOR_CREDITO,
+	SUM(RESULTADO_SALDO_INICIAL.VALOR_DEBITO) AS VALOR_DEBITO
+
+	FROM
+	(
+		SELECT
+		{{if eq .Agrupamento "orgao"}}
+		TO_CHAR(RESULTADO_SALDO_MENSAL.CD_ORGAO) AS CD_AGRUPAMENTO,
+		RESULTADO_SALDO_MENSAL.DS_ORGAO AS DS_AGRUPAMENTO,
+		{{else if or (eq .Agrupamento "unidade_orcamentaria") (eq .Agrupamento "")}}
+		TO_CHAR(RESULTADO_SALDO_MENSAL.CD_UNIDADE_ORCAMENTARIA) AS CD_AGRUPAMENTO,
+		RESULTADO_SALDO_MENSAL.DS_UNIDADE_ORCAMENTARIA AS DS_AGRUPAMENTO,
+		{{else if eq .Agrupamento "unidade_gestora"}}
+		TO_CHAR(RESULTADO_SALDO_MENSAL.CD_UNIDADE_GESTORA) AS CD_AGRUPAMENTO,
+		RESULTADO_SALDO_MENSAL.DS_UNIDADE_GESTORA AS DS_AGRUPAMENTO,
+		{{else}}
+		' ' AS CD_AGRUPAMENTO,
+		' ' AS DS_AGRUPAMENTO,
+		{{end}}
+
+		{{if or (eq .Agrupamento "unidade_orcamentaria") (eq .Agrupamento "unidade_gestora") (eq .Agrupamento "")}}
+		TO_CHAR(RESULTADO_SALDO_MENSAL.CD_UNIDADE_ORCAMENTARIA) AS CD_UNIDADE_ORCAMENTARIA,
+		RESULTADO_SALDO_MENSAL.DS_UNIDADE_ORCAMENTARIA,
+		{{else}}
+		' ' AS CD_UNIDADE_ORCAMENTARIA,
+		' ' AS DS_UNIDADE_ORCAMENTARIA,
+		{{end}}
+
+		RESULTADO_SALDO_MENSAL.IDEN_CONTA_CONTABIL,
+		RESULTADO_SALDO_MENSAL.CONTA_EXPLOSAO,
+		RESULTADO_SALDO_MENSAL.CODG_CONTA_CONTABIL,
+		RESULTADO_SALDO_MENSAL.NOME_CONTA_CONTABIL,
+		RESULTADO_SALDO_MENSAL.NATUREZA_SALDO,
+		RESULTADO_SALDO_MENSAL.VALOR_CREDITO,
+		RESULTADO_SALDO_MENSAL.VALOR_DEBITO,
+		NVL(SI.SALDO_ABERTURA, 0) - RESULTADO_SALDO_MENSAL.SALDO_ANTERIOR AS SALDO_ANTERIOR
+
+		FROM
+		(
+			SELECT
+			UO.CD_EXERCICIO,
+			UG.ID_UNIDADE_GESTORA,
+			UG.CD_UNIDADE_GESTORA,
+			UG.DS_UNIDADE_GESTORA,
+			UO.CD_UNIDADE_ORCAMENTARIA,
+			UO.DS_UNIDADE_ORCAMENTARIA,
+			NVL(ORG.CD_ORGAO, 0) AS CD_ORGAO,
+			NVL(ORG.DS_ORGAO, ' ') AS DS_ORGAO,
+			CC.IDEN_CONTA_CONTABIL,
+			CC.CONTA_EXPLOSAO,
+			CC.CODG_CONTA_CONTABIL,
+			CC.NOME_CONTA_CONTABIL,
+			NVL(DOMINIO_NATUREZA.CD_ITEM_DOMINIO, 0) AS NATUREZA_SALDO,
+
+			{{range $i, $mes := .Meses}}
+			{{if $i}}+{{end}} SUM(NVL(SM.VALR_CRE_{{$mes}}, 0))
+			{{end}}
+			AS VALOR_CREDITO,
+
+			{{range $i, $mes := .Meses}}
+			{{if $i}}+{{end}} SUM(NVL(SM.VALR_DEB_{{$mes}}, 0))
+			{{end}}
+			AS VALOR_DEBITO,
+
+			{{if .MesSaldoAnteriorNome}}
+			SUM(NVL(SM.VALR_{{.MesSaldoAnteriorNome}}, 0)) AS SALDO_ANTERIOR
+			{{else}}
+			SUM(0) AS SALDO_ANTERIOR
+			{{end}}
+
+			FROM
+			{{template "juncoesFIP215" .}}
+
+			{{template "filtrosFIP215" .}}
+
+			GROUP BY
+			UO.CD_EXERCICIO,
+			UG.ID_UNIDADE_GESTORA,
+			UG.CD_UNIDADE_GESTORA,
+			UG.DS_UNIDADE_GESTORA,
+			UO.CD_UNIDADE_ORCAMENTARIA,
+			UO.DS_UNIDADE_ORCAMENTARIA,
+			ORG.CD_ORGAO,
+			ORG.DS_ORGAO,
+			CC.IDEN_CONTA_CONTABIL,
+			CC.CONTA_EXPLOSAO,
+			CC.CODG_CONTA_CONTABIL,
+			CC.NOME_CONTA_CONTABIL,
+			DOMINIO_NATUREZA.CD_ITEM_DOMINIO
+		) RESULTADO_SALDO_MENSAL
+		LEFT JOIN
+		(
+			SELECT
+			CD_EXERCICIO,
+			ID_UNIDADE_GESTORA,
+			IDEN_CONTA_CONTABIL,
+			SUM(SALDO_ABERTURA) AS SALDO_ABERTURA
+
+			FROM ACWTB0197
+
+			GROUP BY
+			CD_EXERCICIO,
+			ID_UNIDADE_GESTORA,
+			IDEN_CONTA_CONTABIL
+		) SI ON SI.CD_EXERCICIO = RESULTADO_SALDO_MENSAL.CD_EXERCICIO
+		AND SI.ID_UNIDADE_GESTORA = RESULTADO_SALDO_MENSAL.ID_UNIDADE_GESTORA
+		AND SI.IDEN_CONTA_CONTABIL = RESULTADO_SALDO_MENSAL.IDEN_CONTA_CONTABIL
+	) RESULTADO_SALDO_INICIAL
+
+	GROUP BY
+	RESULTADO_SALDO_INICIAL.CD_AGRUPAMENTO,
+	RESULTADO_SALDO_INICIAL.DS_AGRUPAMENTO,
+	RESULTADO_SALDO_INICIAL.CD_UNIDADE_ORCAMENTARIA,
+	RESULTADO_SALDO_INICIAL.DS_UNIDADE_ORCAMENTARIA,
+	RESULTADO_SALDO_INICIAL.IDEN_CONTA_CONTABIL,
+	RESULTADO_SALDO_INICIAL.CONTA_EXPLOSAO,
+	RESULTADO_SALDO_INICIAL.CODG_CONTA_CONTABIL,
+	RESULTADO_SALDO_INICIAL.NOME_CONTA_CONTABIL,
+	RESULTADO_SALDO_INICIAL.NATUREZA_SALDO`
+
+/*** Queries SQL ***/
+
+// montarQueryFIP215 executa o template de uma query, que pode usar os templates
+// "juncoesFIP215" e "filtrosFIP215", e devolve a query compactada.
+func montarQueryFIP215(nome string, queryTemplate string, dados any) (string, *echo.HTTPError) {
 	var sqlQuery strings.Builder
 
-	var contasContabeis relatorioFIP215
-
-	queryContaContabeisTemplate := `SELECT
-			                            CONTA_CONTABIL.IDEN_CONTA_CONTABIL,
-																  NVL(TO_CHAR(CONTA_CONTABIL.CONTA_EXPLOSAO), ' ') AS CONTA_EXPLOSAO,
-			                            CONTA_CONTABIL.CODG_CONTA_CONTABIL,
-			                            CONTA_CONTABIL.NOME_CONTA_CONTABIL
-
-			                            FROM
-			                            ACWTB0032 CONTA_CONTABIL,
-			                            ACWTB0069 SUB_ITEM,
-			                            ACWTB0068 ITEM,
-			                            ACWTB0067 SUB_ELEMENTO,
-			                            ACWTB0066 ELEMENTO,
-			                            ACWTB0065 SUB_GRUPO,
-			                            ACWTB0064 GRUPO,
-			                            ACWTB0063 CLASSE,
-			                            ITEM_DOMINIO FLAG_ESCRITURACAO
-
-			                            WHERE CONTA_CONTABIL.IDEN_SUBITEM = SUB_ITEM.IDEN_SUBITEM
-			                            AND SUB_ITEM.IDEN_ITEM = ITEM.IDEN_ITEM
-			                            AND ITEM.IDEN_SUBELEMENTO = SUB_ELEMENTO.IDEN_SUBELEMENTO
-			                            AND SUB_ELEMENTO.IDEN_ELEMENTO = ELEMENTO.IDEN_ELEMENTO
-			                            AND ELEMENTO.IDEN_SUBGRUPO = SUB_GRUPO.IDEN_SUBGRUPO
-			                            AND SUB_GRUPO.IDEN_GRUPO = GRUPO.IDEN_GRUPO
-			                            AND GRUPO.IDEN_CLASSE = CLASSE.IDEN_CLASSE
-			                            AND CONTA_CONTABIL.FLAG_ESCRITURACAO = FLAG_ESCRITURACAO.ID_ITEM_DOMINIO
-			                            AND CLASSE.CD_EXERCICIO = {{.}}
-			                            AND FLAG_ESCRITURACAO.CD_ITEM_DOMINIO = 2`
-
-	tmplContaExplosao, err := template.New("queryContasContabeis").Parse(queryContaContabeisTemplate)
+	tmpl, err := template.New(nome).Funcs(FuncoesTemplate).Parse(templateJuncoesRelatorioFIP215 + templateFiltrosRelatorioFIP215 + queryTemplate)
 
 	if err != nil {
-		log.Printf("RelatorioFIP215Handler: %v", err)
-		return ErroMontagemTemplate
+		log.Printf("%s: %v", nome, err)
+		return "", ErroMontagemTemplate
 	}
 
-	err = tmplContaExplosao.Execute(&sqlQuery, parametros.AnoExercicio)
+	err = tmpl.Execute(&sqlQuery, dados)
 
 	if err != nil {
-		log.Printf("RelatorioFIP215Handler: %v", err)
-		return ErroExecucaoTemplate
+		log.Printf("%s: %v", nome, err)
+		return "", ErroExecucaoTemplate
 	}
 
-	compactSqlQuery := strings.Join(strings.Fields(sqlQuery.String()), " ")
-	log.Printf("RelatorioFIP215Handler: %s", compactSqlQuery)
-	rows, err := Db.Query(compactSqlQuery)
+	return strings.Join(strings.Fields(sqlQuery.String()), " "), nil
+}
+
+// consultarContasContabeisSinteticasFIP215 consulta as contas sintéticas do
+// plano de contas do exercício, com os valores zerados.
+func consultarContasContabeisSinteticasFIP215(anoExercicio int) ([]dadoRelatorioFIP215, *echo.HTTPError) {
+	var contasContabeis []dadoRelatorioFIP215
 
-	sqlQuery.Reset()
+	compactSqlQuery, erro := montarQueryFIP215("queryContasContabeis", queryContasContabeisSinteticasFIP215, anoExercicio)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	log.Printf("consultarContasContabeisSinteticasFIP215: %s", compactSqlQuery)
+	rows, err := Db.Query(compactSqlQuery)
 
 	if err != nil {
-		log.Printf("RelatorioFIP215Handler: %v", err)
-		return ErroConsultaBancoDados
+		log.Printf("consultarContasContabeisSinteticasFIP215: %v", err)
+		return nil, ErroConsultaBancoDados
 	}
 
 	defer rows.Close()
 
 	for rows.Next() {
 		var dado dadoRelatorioFIP215
+		var naturezaSaldo int
 
 		if err := rows.Scan(
 			&dado.IDContaContabil,
 			&dado.IDContaContabilExplosao,
 			&dado.CodigoContaContabil,
 			&dado.NomeContaContabil,
+			&naturezaSaldo,
 		); err != nil {
-			log.Printf("RelatorioFIP215Handler: %v", err)
-			return ErroConsultaLinhaBancoDados
+			log.Printf("consultarContasContabeisSinteticasFIP215: %v", err)
+			return nil, ErroConsultaLinhaBancoDados
 		}
 
 		if dado.IDContaContabilExplosao == " " {
 			dado.IDContaContabilExplosao = ""
 		}
 
-		dado.CodigoUnidadeOrcamentaria = ""
-		dado.NomeUnidadeOrcamentaria = ""
-		dado.SaldoAnterior = 0.0
-		dado.ValorCredito = 0.0
-		dado.ValorDebito = 0.0
-		dado.SaldoAtual = 0.0
-		contasContabeis.Dados = append(contasContabeis.Dados, dado)
+		dado.NaturezaSaldo = NaturezaSaldoParaNome[naturezaSaldo]
+
+		contasContabeis = append(contasContabeis, dado)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Printf("RelatorioFIP215Handler: %v", err)
-		return ErroRedeOuResultadoBancoDados
-	}
-
-	var contasContabeisEspecificas relatorioFIP215
-
-	queryContasContabeisEspecificasTemplate := `SELECT
-										                          RESULTADO_SALDO_INICIAL.CD_UNIDADE_ORCAMENTARIA,
-										                          RESULTADO_SALDO_INICIAL.DS_UNIDADE_ORCAMENTARIA,
-										                          RESULTADO_SALDO_INICIAL.IDEN_CONTA_CONTABIL,
-										                          RESULTADO_SALDO_INICIAL.CONTA_EXPLOSAO,
-										                          RESULTADO_SALDO_INICIAL.CODG_CONTA_CONTABIL,
-										                          RESULTADO_SALDO_INICIAL.NOME_CONTA_CONTABIL,
-										                          RESULTADO_SALDO_INICIAL.SALDO_ANTERIOR,
-										                          RESULTADO_SALDO_INICIAL.VALOR_CREDITO,
-										                          RESULTADO_SALDO_INICIAL.VALOR_DEBITO
-
-										                          FROM
-										                          (
-										                            SELECT
-										                            RESULTADO_SALDO_MENSAL.CD_EXERCICIO,
-										                            RESULTADO_SALDO_MENSAL.ID_UNIDADE_ORCAMENTARIA,
-										                            RESULTADO_SALDO_MENSAL.CD_UNIDADE_ORCAMENTARIA,
-										                            RESULTADO_SALDO_MENSAL.DS_UNIDADE_ORCAMENTARIA,
-										                            RESULTADO_SALDO_MENSAL.IDEN_CONTA_CONTABIL,
-										                            RESULTADO_SALDO_MENSAL.CONTA_EXPLOSAO,
-										                            RESULTADO_SALDO_MENSAL.CODG_CONTA_CONTABIL,
-										                            RESULTADO_SALDO_MENSAL.NOME_CONTA_CONTABIL,
-										                            NVL(RESULTADO_SALDO_MENSAL.VALOR_CREDITO, 0) AS VALOR_CREDITO,
-										                            NVL(RESULTADO_SALDO_MENSAL.VALOR_DEBITO, 0) AS VALOR_DEBITO,
-										                            COALESCE(SUM(SI.SALDO_ABERTURA), 0) - RESULTADO_SALDO_MENSAL.SALDO_ANTERIOR AS SALDO_ANTERIOR
-										                            
-										                            FROM
-										                            (
-										                           	  SELECT
-										                           	  UO.CD_EXERCICIO,
-										                           	  UO.ID_UNIDADE_ORCAMENTARIA,
-										                           	  UO.CD_UNIDADE_ORCAMENTARIA,
-										                           	  UO.DS_UNIDADE_ORCAMENTARIA,
-										                          		
-										                          		{{if .TipoPoder}}
-										                          		ORG.FLG_TP_PODER,
-										                          		{{end}}
-
-										                           	  CC.IDEN_CONTA_CONTABIL,
-										                           	  CC.CONTA_EXPLOSAO,
-										                           	  CC.CODG_CONTA_CONTABIL,
-										                           	  CC.NOME_CONTA_CONTABIL,
-
-										                          		{{range .Meses}}
-										                           	  SUM(NVL(SM.VALR_CRE_{{.}}, 0)) +
-										                          		{{end}}
-										                          		
-										                           	  SUM(NVL(SM.VALR_CRE_{{.MesReferenciaNome}}, 0)) AS VALOR_CREDITO,
-
-										                          		{{range .Meses}}
-										                           	  SUM(NVL(SM.VALR_DEB_{{.}}, 0)) +
-										                          		{{end}}
-
-										                           	  SUM(NVL(SM.VALR_DEB_{{.MesReferenciaNome}}, 0)) AS VALOR_DEBITO,
-
-										                          		{{if eq .MesReferencia 1}}
-										                           	  SUM(0) AS SALDO_ANTERIOR
-										                          		{{else}}
-										                           	  SUM(NVL(SM.VALR_{{.MesAnteriorReferenciaNome}}, 0)) AS SALDO_ANTERIOR
-										                          		{{end}}
-										                           	  
-										                           	  FROM
-										                           	  UNIDADE_ORCAMENTARIA UO 
-										                           	  LEFT JOIN
-										                           	  UNIDADE_GESTORA UG ON (UG.ID_UNIDADE_ORCAMENTARIA = UO.ID_UNIDADE_ORCAMENTARIA)
-										                           	  LEFT JOIN
-										                           	  ORGAO ORG ON ORG.ID_ORGAO = UO.ID_ORGAO 
-										                           	  LEFT JOIN
-										                           	  ACWTB0215 SM ON (SM.ID_UNIDADE_GESTORA = UG.ID_UNIDADE_GESTORA)
-										                           	  LEFT JOIN
-										                           	  ACWTB0032 CC ON (CC.IDEN_CONTA_CONTABIL = SM.IDEN_CONTA_CONTABIL)
-										                           	  LEFT JOIN
-										                           	  ITEM_DOMINIO DOMINIO_RP ON (CC.FLAG_CONTA_CONTABIL_RP = DOMINIO_RP.ID_ITEM_DOMINIO)
-										                           	  LEFT JOIN
-										                           	  ITEM_DOMINIO DOMINIO_ENCERRA ON (CC.FLAG_TIPO_ENCERRAMENTO = DOMINIO_ENCERRA.ID_ITEM_DOMINIO)
-
-									                                WHERE UO.CD_EXERCICIO = {{.AnoExercicio}}
-
-										                          		{{if .UnidadeGestora}}
-																									AND UG.CD_UNIDADE_GESTORA = {{.UnidadeGestora}}
-										                          		{{end}}
-										                          		
-										                          		{{if .UnidadeOrcamentaria}}
-										                          		AND UO.CD_UNIDADE_ORCAMENTARIA = {{.UnidadeOrcamentaria}}
-										                          		{{end}}
-
-										                          		{{if .TipoAdministracao}}
-																									AND LOWER(UO.FLG_TIPO_ADM) = '{{.TipoAdministracao}}'
-										                          		{{end}}
-
-										                          		{{if eq .MesContabil 1}}
-										                           	  AND SM.FLAG_MES_CONTABIL = 1428
-										                          		{{else if eq .MesContabil 2}}
-										                           	  AND SM.FLAG_MES_CONTABIL = 1429
-										                          		{{else if eq .MesContabil 3}}
-										                           	  AND SM.FLAG_MES_CONTABIL = 1430
-										                          		{{else if eq .MesContabil 4}}
-										                           	  AND SM.FLAG_MES_CONTABIL != 10120
-										                          		{{end}}
-
-										                          		{{if eq .TipoPoder 1}}
-										                           	  AND ORG.FLG_TP_PODER = {{.TipoPoder}}
-										                           	  AND UO.CD_UNIDADE_ORCAMENTARIA <> 08101
-										                           	  AND UO.CD_UNIDADE_ORCAMENTARIA <> 08601
-										                          		{{else if or (eq .TipoPoder 2) (eq .TipoPoder 3)}}
-										                           	  AND ORG.FLG_TP_PODER = {{.TipoPoder}}
-										                          		{{else if eq .TipoPoder 4}}
-										                           	  AND (UO.CD_UNIDADE_ORCAMENTARIA <> 08101 OR UO.CD_UNIDADE_ORCAMENTARIA <> 08601)
-										                          		{{end}}
-
-										                          		{{if .IndicativoContaContabilRP}}
-										                          		AND DOMINIO_RP.CD_ITEM_DOMINIO = {{.IndicativoContaContabilRP}}
-										                          		{{end}}
-
-										                          		{{if .TipoEncerramento}}
-										                          		AND DOMINIO_ENCERRA.CD_ITEM_DOMINIO = {{.TipoEncerramento}}
-										                          		{{end}}
-
-										                          		{{if eq .IndicativoSuperavitFinanceiro 1}}
-										                          		AND CC.FLAG_SUPERAVIT_FINANCEIRO = 856
-										                          		{{else if eq .IndicativoSuperavitFinanceiro 2}}
-										                          		AND CC.FLAG_SUPERAVIT_FINANCEIRO = 857
-										                          		{{end}}
-
-										                          		{{if eq .IndicativoComposicaoMSC 1}}
-										                          		AND CC.FLAG_COMPOSICAO_MSC = 856
-										                          		{{else if eq .IndicativoComposicaoMSC 2}}
-										                          		AND CC.FLAG_COMPOSICAO_MSC = 857
-										                          		{{end}}
-
-										                          		{{if .ConsolidadoRPPS}}
-										                          		AND UO.CD_UNIDADE_ORCAMENTARIA IN (15301, 15601, 15602, 15603)
-										                          		{{end}}
-										                           	  
-										                           	  GROUP BY
-										                           	  UO.CD_EXERCICIO,
-										                           	  UO.CD_UNIDADE_ORCAMENTARIA,
-										                           	  UO.DS_UNIDADE_ORCAMENTARIA,
-										                           	  UO.ID_UNIDADE_ORCAMENTARIA,
-
-										                          		{{if .TipoPoder}}
-										                          		ORG.FLG_TP_PODER,
-										                          		{{end}}
-
-										                           	  CC.IDEN_CONTA_CONTABIL,
-										                           	  CC.CONTA_EXPLOSAO,
-										                           	  CC.CODG_CONTA_CONTABIL,
-										                           	  CC.NOME_CONTA_CONTABIL
-										                            ) RESULTADO_SALDO_MENSAL
-										                            LEFT JOIN
-										                            UNIDADE_GESTORA UG ON (UG.ID_UNIDADE_ORCAMENTARIA = RESULTADO_SALDO_MENSAL.ID_UNIDADE_ORCAMENTARIA)
-										                            LEFT JOIN
-										                            ACWTB0197 SI ON SI.CD_EXERCICIO = RESULTADO_SALDO_MENSAL.CD_EXERCICIO
-										                            AND SI.ID_UNIDADE_GESTORA = UG.ID_UNIDADE_GESTORA
-										                            AND SI.IDEN_CONTA_CONTABIL = RESULTADO_SALDO_MENSAL.IDEN_CONTA_CONTABIL
-
-										                            GROUP BY
-										                            RESULTADO_SALDO_MENSAL.CD_EXERCICIO,
-										                            RESULTADO_SALDO_MENSAL.ID_UNIDADE_ORCAMENTARIA,
-										                            RESULTADO_SALDO_MENSAL.CD_UNIDADE_ORCAMENTARIA,
-										                            RESULTADO_SALDO_MENSAL.DS_UNIDADE_ORCAMENTARIA,
-										                            RESULTADO_SALDO_MENSAL.IDEN_CONTA_CONTABIL,
-										                            RESULTADO_SALDO_MENSAL.CONTA_EXPLOSAO,
-										                            RESULTADO_SALDO_MENSAL.CODG_CONTA_CONTABIL,
-										                            RESULTADO_SALDO_MENSAL.NOME_CONTA_CONTABIL,
-										                            RESULTADO_SALDO_MENSAL.SALDO_ANTERIOR,
-										                            RESULTADO_SALDO_MENSAL.VALOR_CREDITO,
-										                            RESULTADO_SALDO_MENSAL.VALOR_DEBITO
-										                          ) RESULTADO_SALDO_INICIAL
-										                          LEFT JOIN
-										                          UNIDADE_GESTORA UG ON (UG.ID_UNIDADE_ORCAMENTARIA = RESULTADO_SALDO_INICIAL.ID_UNIDADE_ORCAMENTARIA)
-
-										                          GROUP BY
-										                          RESULTADO_SALDO_INICIAL.CD_UNIDADE_ORCAMENTARIA,
-										                          RESULTADO_SALDO_INICIAL.DS_UNIDADE_ORCAMENTARIA,
-										                          RESULTADO_SALDO_INICIAL.IDEN_CONTA_CONTABIL,
-										                          RESULTADO_SALDO_INICIAL.CONTA_EXPLOSAO,
-										                          RESULTADO_SALDO_INICIAL.CODG_CONTA_CONTABIL,
-										                          RESULTADO_SALDO_INICIAL.NOME_CONTA_CONTABIL,
-										                          RESULTADO_SALDO_INICIAL.SALDO_ANTERIOR,
-										                          RESULTADO_SALDO_INICIAL.VALOR_CREDITO,
-										                          RESULTADO_SALDO_INICIAL.VALOR_DEBITO`
-
-	tmplMain, err := template.New("queryMain").Parse(queryContasContabeisEspecificasTemplate)
+		log.Printf("consultarContasContabeisSinteticasFIP215: %v", err)
+		return nil, ErroRedeOuResultadoBancoDados
+	}
 
-	if err != nil {
-		log.Printf("RelatorioFIP215Handler: %v", err)
-		return ErroMontagemTemplate
+	return contasContabeis, nil
+}
+
+// consultarRelatorioFIP215 consulta o balancete e consolida os valores das
+// contas analíticas nas contas sintéticas.
+func consultarRelatorioFIP215(parametros parametrosRelatorioFIP215) (relatorioFIP215, *echo.HTTPError) {
+	/*** Consulta no Banco de Dados ***/
+	contasContabeis := relatorioFIP215{Agrupamento: parametros.Agrupamento}
+
+	contasContabeisSinteticas, erro := consultarContasContabeisSinteticasFIP215(parametros.AnoExercicio)
+
+	if erro != nil {
+		return contasContabeis, erro
 	}
 
-	err = tmplMain.Execute(&sqlQuery, parametros)
+	var contasContabeisEspecificas []dadoRelatorioFIP215
 
-	if err != nil {
-		log.Printf("RelatorioFIP215Handler: %v", err)
-		return ErroExecucaoTemplate
+	compactSqlQuery, erro := montarQueryFIP215("queryMain", queryContasContabeisEspecificasFIP215, parametros)
+
+	if erro != nil {
+		return contasContabeis, erro
 	}
 
-	compactSqlQuery = strings.Join(strings.Fields(sqlQuery.String()), " ")
 	log.Printf("RelatorioFIP215Handler: %s", compactSqlQuery)
-	rows, err = Db.Query(compactSqlQuery)
+	rows, err := Db.Query(compactSqlQuery)
 
 	if err != nil {
 		log.Printf("RelatorioFIP215Handler: %v", err)
-		return ErroConsultaBancoDados
+		return contasContabeis, ErroConsultaBancoDados
 	}
 
 	defer rows.Close()
 
 	for rows.Next() {
 		var dado dadoRelatorioFIP215
+		var naturezaSaldo int
 
 		if err := rows.Scan(
+			&dado.CodigoAgrupamento,
+			&dado.NomeAgrupamento,
 			&dado.CodigoUnidadeOrcamentaria,
 			&dado.NomeUnidadeOrcamentaria,
 			&dado.IDContaContabil,
 			&dado.IDContaContabilExplosao,
 			&dado.CodigoContaContabil,
 			&dado.NomeContaContabil,
+			&naturezaSaldo,
 			&dado.SaldoAnterior,
 			&dado.ValorCredito,
 			&dado.ValorDebito,
 		); err != nil {
 			log.Printf("RelatorioFIP215Handler: %v", err)
-			return ErroConsultaLinhaBancoDados
+			return contasContabeis, ErroConsultaLinhaBancoDados
+		}
+
+		if dado.CodigoAgrupamento == " " {
+			dado.CodigoAgrupamento = ""
+			dado.NomeAgrupamento = ""
 		}
 
+		if dado.CodigoUnidadeOrcamentaria == " " {
+			dado.CodigoUnidadeOrcamentaria = ""
+			dado.NomeUnidadeOrcamentaria = ""
+		}
+
+		dado.ContaEscrituravel = true
+		dado.NaturezaSaldo = NaturezaSaldoParaNome[naturezaSaldo]
 		dado.SaldoAtual = dado.ValorCredito - dado.ValorDebito + dado.SaldoAnterior
 
-		contasContabeisEspecificas.Dados = append(contasContabeisEspecificas.Dados, dado)
+		contasContabeisEspecificas = append(contasContabeisEspecificas, dado)
 	}
 
 	if err := rows.Err(); err != nil {
 		log.Printf("RelatorioFIP215Handler: %v", err)
-		return ErroRedeOuResultadoBancoDados
+		return contasContabeis, ErroRedeOuResultadoBancoDados
 	}
 	/*** Consulta no Banco de Dados ***/
 
 	/*** Lógica Adicional ***/
-	contasContabeis.Dados = append(contasContabeis.Dados, contasContabeisEspecificas.Dados...)
+	// Na consolidação, os saldos e os movimentos das contas intra-OFSS se
+	// anulam entre os poderes e órgãos e são retirados do balancete. Como a
+	// contrapartida não é registrada, os valores eliminados são listados por
+	// agrupamento e conta analítica.
+	if parametros.EliminarIntra {
+		var contasMantidas []dadoRelatorioFIP215
+
+		for _, contaContabil := range contasContabeisEspecificas {
+			if !contaContabilIntraOFSS(contaContabil.CodigoContaContabil) {
+				contasMantidas = append(contasMantidas, contaContabil)
+				continue
+			}
+
+			contaContabil.ValorDebito = math.Round(contaContabil.ValorDebito*100.0) / 100.0
+			contaContabil.ValorCredito = math.Round(contaContabil.ValorCredito*100.0) / 100.0
+			contaContabil.SaldoAtual = math.Round(contaContabil.SaldoAtual*100.0) / 100.0
+			contaContabil.SaldoAnterior = math.Round(contaContabil.SaldoAnterior*100.0) / 100.0
+
+			contaContabil.ValorSaldoAnterior, contaContabil.IndicadorSaldoAnterior = IndicadorSaldo(contaContabil.SaldoAnterior)
+			contaContabil.ValorSaldoAtual, contaContabil.IndicadorSaldoAtual = IndicadorSaldo(contaContabil.SaldoAtual)
+			contaContabil.SaldoInvertido = SaldoInvertido(contaContabil.NaturezaSaldo, contaContabil.IndicadorSaldoAtual)
 
-	sort.Slice(contasContabeis.Dados, func(i, j int) bool {
-		for in := len(contasContabeis.Dados[i].CodigoContaContabil) - 1; in >= 0; in-- {
-			if contasContabeis.Dados[i].CodigoContaContabil[in] != '0' && contasContabeis.Dados[i].CodigoContaContabil[in] != '.' {
-				return true
+			contasContabeis.Eliminacoes = append(contasContabeis.Eliminacoes, contaContabil)
+		}
+
+		contasContabeisEspecificas = contasMantidas
+
+		sort.SliceStable(contasContabeis.Eliminacoes, func(i, j int) bool {
+			if contasContabeis.Eliminacoes[i].CodigoAgrupamento != contasContabeis.Eliminacoes[j].CodigoAgrupamento {
+				return contasContabeis.Eliminacoes[i].CodigoAgrupamento < contasContabeis.Eliminacoes[j].CodigoAgrupamento
 			}
-			if contasContabeis.Dados[j].CodigoContaContabil[in] != '0' && contasContabeis.Dados[j].CodigoContaContabil[in] != '.' {
-				return false
+
+			return contasContabeis.Eliminacoes[i].CodigoContaContabil < contasContabeis.Eliminacoes[j].CodigoContaContabil
+		})
+	}
+
+	// Cada agrupamento recebe cópias das contas sintéticas superiores às suas
+	// contas analíticas, que totalizam apenas as analíticas do agrupamento.
+	var agrupamentos []string
+	contasContabeisPorAgrupamento := map[string][]dadoRelatorioFIP215{}
+
+	for _, contaContabil := range contasContabeisEspecificas {
+		if _, ok := contasContabeisPorAgrupamento[contaContabil.CodigoAgrupamento]; !ok {
+			agrupamentos = append(agrupamentos, contaContabil.CodigoAgrupamento)
+		}
+
+		contasContabeisPorAgrupamento[contaContabil.CodigoAgrupamento] = append(contasContabeisPorAgrupamento[contaContabil.CodigoAgrupamento], contaContabil)
+	}
+
+	arvore := novaArvoreContasContabeisFIP215(contasContabeisSinteticas)
+
+	for _, agrupamento := range agrupamentos {
+		contasAgrupamento := contasContabeisPorAgrupamento[agrupamento]
+		contasContabeis.Dados = append(contasContabeis.Dados, arvore.totalizar(contasAgrupamento, contasAgrupamento[0])...)
+	}
+
+	// Sem contas analíticas no período, o consolidado traz o plano de contas
+	// sintético zerado.
+	if len(agrupamentos) == 0 && parametros.Agrupamento == "consolidado" {
+		contasContabeis.Dados = finalizarContasContabeisFIP215(copiarContasContabeisSinteticasFIP215(contasContabeisSinteticas, dadoRelatorioFIP215{}))
+	}
+
+	// O total geral soma as contas analíticas de todos os agrupamentos.
+	if parametros.Agrupamento != "consolidado" && parametros.Agrupamento != "" {
+		var contasTotal []dadoRelatorioFIP215
+		indiceContasContabeis := map[string]int{}
+
+		for _, contaContabil := range contasContabeisEspecificas {
+			i, ok := indiceContasContabeis[contaContabil.CodigoContaContabil]
+
+			if !ok {
+				i = len(contasTotal)
+				indiceContasContabeis[contaContabil.CodigoContaContabil] = i
+				contasTotal = append(contasTotal, copiarContasContabeisSinteticasFIP215([]dadoRelatorioFIP215{contaContabil}, dadoRelatorioFIP215{})...)
 			}
+
+			contasTotal[i].SaldoAnterior += contaContabil.SaldoAnterior
+			contasTotal[i].ValorCredito += contaContabil.ValorCredito
+			contasTotal[i].ValorDebito += contaContabil.ValorDebito
+			contasTotal[i].SaldoAtual += contaContabil.SaldoAtual
 		}
 
-		return false
-	})
+		contasContabeis.Total = arvore.totalizar(contasTotal, dadoRelatorioFIP215{})
+	}
 
-	for i, contaContabil := range contasContabeis.Dados {
-		codigoContaContabil := strings.ReplaceAll(contaContabil.CodigoContaContabil, ".", "")
-		indiceUltimoDigitoNaoZero := 0
+	contasContabeis.Dados = filtrarContasContabeisFIP215(parametros, contasContabeis.Dados)
+	contasContabeis.Total = filtrarContasContabeisFIP215(parametros, contasContabeis.Total)
 
-		for j, caractere := range codigoContaContabil {
-			if caractere != '0' {
-				indiceUltimoDigitoNaoZero = j
+	// Sem o parâmetro agrupamento, o balancete mantém o formato original, com
+	// as contas por unidade orçamentária e sem os campos de agrupamento.
+	if parametros.Agrupamento == "" {
+		for _, contas := range [][]dadoRelatorioFIP215{contasContabeis.Dados, contasContabeis.Eliminacoes} {
+			for i := range contas {
+				contas[i].CodigoAgrupamento = ""
+				contas[i].NomeAgrupamento = ""
 			}
 		}
+	}
+	/*** Lógica Adicional ***/
+
+	return contasContabeis, nil
+}
 
-		digitosNaoRelevantes := []int{5, 7, 9}
-		if slices.Contains(digitosNaoRelevantes, indiceUltimoDigitoNaoZero) {
-			indiceUltimoDigitoNaoZero++
+// copiarContasContabeisSinteticasFIP215 copia as contas com os valores zerados e
+// com o agrupamento e a unidade orçamentária da conta de referência.
+func copiarContasContabeisSinteticasFIP215(contasContabeis []dadoRelatorioFIP215, referencia dadoRelatorioFIP215) []dadoRelatorioFIP215 {
+	copia := make([]dadoRelatorioFIP215, len(contasContabeis))
+
+	for i, contaContabil := range contasContabeis {
+		copia[i] = dadoRelatorioFIP215{
+			CodigoAgrupamento:         referencia.CodigoAgrupamento,
+			NomeAgrupamento:           referencia.NomeAgrupamento,
+			CodigoUnidadeOrcamentaria: referencia.CodigoUnidadeOrcamentaria,
+			NomeUnidadeOrcamentaria:   referencia.NomeUnidadeOrcamentaria,
+			IDContaContabil:           contaContabil.IDContaContabil,
+			IDContaContabilExplosao:   contaContabil.IDContaContabilExplosao,
+			CodigoContaContabil:       contaContabil.CodigoContaContabil,
+			NomeContaContabil:         contaContabil.NomeContaContabil,
+			ContaEscrituravel:         contaContabil.ContaEscrituravel,
+			NaturezaSaldo:             contaContabil.NaturezaSaldo,
 		}
+	}
 
-		codigoPrefixo := codigoContaContabil[:indiceUltimoDigitoNaoZero+1]
+	return copia
+}
 
-		for _, contaContabilInterna := range contasContabeis.Dados {
-			codigoContaContabilInterno := strings.ReplaceAll(contaContabilInterna.CodigoContaContabil, ".", "")
-			indiceUltimoDigitoNaoZeroInterno := 0
+// arvoreContasContabeisFIP215 indexa as contas sintéticas pelo prefixo do
+// código, calculado uma única vez por consulta, para que a totalização de cada
+// agrupamento percorra apenas as contas superiores das suas contas analíticas.
+type arvoreContasContabeisFIP215 struct {
+	sinteticas []dadoRelatorioFIP215
+	indices    map[string]int
+}
 
-			for j, caractere := range codigoContaContabilInterno {
-				if caractere != '0' {
-					indiceUltimoDigitoNaoZeroInterno = j
-				}
-			}
+func novaArvoreContasContabeisFIP215(contasContabeisSinteticas []dadoRelatorioFIP215) arvoreContasContabeisFIP215 {
+	arvore := arvoreContasContabeisFIP215{
+		sinteticas: contasContabeisSinteticas,
+		indices:    make(map[string]int, len(contasContabeisSinteticas)),
+	}
+
+	for i, contaContabil := range contasContabeisSinteticas {
+		prefixo, _ := prefixoContaContabil(contaContabil.CodigoContaContabil)
+		arvore.indices[prefixo] = i
+	}
 
-			if slices.Contains(digitosNaoRelevantes, indiceUltimoDigitoNaoZeroInterno) {
-				indiceUltimoDigitoNaoZeroInterno++
+	return arvore
+}
+
+// totalizar soma cada conta analítica nas cópias das suas contas sintéticas
+// superiores, com o agrupamento e a unidade orçamentária da conta de
+// referência, e retorna as analíticas e as sintéticas usadas, arredondadas e
+// ordenadas pelo código.
+func (a arvoreContasContabeisFIP215) totalizar(contasContabeisAnaliticas []dadoRelatorioFIP215, referencia dadoRelatorioFIP215) []dadoRelatorioFIP215 {
+	var sinteticas []dadoRelatorioFIP215
+	indicesSinteticas := map[int]int{}
+
+	for _, contaContabil := range contasContabeisAnaliticas {
+		codigo, indice := prefixoContaContabil(contaContabil.CodigoContaContabil)
+
+		for nivel := nivelIndiceContaContabil(indice) - 1; nivel >= 1; nivel-- {
+			i, ok := a.indices[codigo[:min(tamanhoCodigoNivelContaContabil(nivel), len(codigo))]]
+
+			if !ok {
+				continue
 			}
 
-			if strings.HasPrefix(codigoContaContabilInterno, codigoPrefixo) {
-				if (indiceUltimoDigitoNaoZero < 4 && indiceUltimoDigitoNaoZeroInterno == indiceUltimoDigitoNaoZero+1) || (indiceUltimoDigitoNaoZero >= 4 && indiceUltimoDigitoNaoZeroInterno == indiceUltimoDigitoNaoZero+2) {
-					contasContabeis.Dados[i].ValorDebito += contaContabilInterna.ValorDebito
-					contasContabeis.Dados[i].ValorCredito += contaContabilInterna.ValorCredito
-					contasContabeis.Dados[i].SaldoAtual += contaContabilInterna.SaldoAtual
-					contasContabeis.Dados[i].SaldoAnterior += contaContabilInterna.SaldoAnterior
-				}
+			j, ok := indicesSinteticas[i]
+
+			if !ok {
+				j = len(sinteticas)
+				indicesSinteticas[i] = j
+				sinteticas = append(sinteticas, copiarContasContabeisSinteticasFIP215(a.sinteticas[i:i+1], referencia)...)
 			}
+
+			sinteticas[j].ValorDebito += contaContabil.ValorDebito
+			sinteticas[j].ValorCredito += contaContabil.ValorCredito
+			sinteticas[j].SaldoAtual += contaContabil.SaldoAtual
+			sinteticas[j].SaldoAnterior += contaContabil.SaldoAnterior
 		}
 	}
 
-	for i := 0; i < len(contasContabeis.Dados); i++ {
-		contasContabeis.Dados[i].ValorDebito = math.Round(contasContabeis.Dados[i].ValorDebito*100.0) / 100.0
-		contasContabeis.Dados[i].ValorCredito = math.Round(contasContabeis.Dados[i].ValorCredito*100.0) / 100.0
-		contasContabeis.Dados[i].SaldoAtual = math.Round(contasContabeis.Dados[i].SaldoAtual*100.0) / 100.0
-		contasContabeis.Dados[i].SaldoAnterior = math.Round(contasContabeis.Dados[i].SaldoAnterior*100.0) / 100.0
+	contasContabeis := make([]dadoRelatorioFIP215, 0, len(contasContabeisAnaliticas)+len(sinteticas))
+	contasContabeis = append(contasContabeis, contasContabeisAnaliticas...)
+	contasContabeis = append(contasContabeis, sinteticas...)
+
+	return finalizarContasContabeisFIP215(contasContabeis)
+}
+
+// finalizarContasContabeisFIP215 arredonda os valores, calcula os indicadores
+// de saldo e ordena as contas pelo código.
+func finalizarContasContabeisFIP215(contasContabeis []dadoRelatorioFIP215) []dadoRelatorioFIP215 {
+	for i := 0; i < len(contasContabeis); i++ {
+		contasContabeis[i].ValorDebito = math.Round(contasContabeis[i].ValorDebito*100.0) / 100.0
+		contasContabeis[i].ValorCredito = math.Round(contasContabeis[i].ValorCredito*100.0) / 100.0
+		contasContabeis[i].SaldoAtual = math.Round(contasContabeis[i].SaldoAtual*100.0) / 100.0
+		contasContabeis[i].SaldoAnterior = math.Round(contasContabeis[i].SaldoAnterior*100.0) / 100.0
+
+		contasContabeis[i].ValorSaldoAnterior, contasContabeis[i].IndicadorSaldoAnterior = IndicadorSaldo(contasContabeis[i].SaldoAnterior)
+		contasContabeis[i].ValorSaldoAtual, contasContabeis[i].IndicadorSaldoAtual = IndicadorSaldo(contasContabeis[i].SaldoAtual)
+		contasContabeis[i].SaldoInvertido = SaldoInvertido(contasContabeis[i].NaturezaSaldo, contasContabeis[i].IndicadorSaldoAtual)
 	}
 
-	sort.Slice(contasContabeis.Dados, func(i, j int) bool {
-		return contasContabeis.Dados[i].CodigoContaContabil < contasContabeis.Dados[j].CodigoContaContabil
+	sort.Slice(contasContabeis, func(i, j int) bool {
+		return contasContabeis[i].CodigoContaContabil < contasContabeis[j].CodigoContaContabil
 	})
-	/*** Lógica Adicional ***/
 
-	return c.JSON(http.StatusOK, contasContabeis)
+	return contasContabeis
+}
+
+// filtrarContasContabeisFIP215 mantém apenas as contas com os valores, o nível
+// e o intervalo de códigos pedidos. Deve ser chamada depois da totalização,
+// para que as contas sintéticas já contenham os valores das contas removidas.
+// As contas superiores de uma conta mantida também são mantidas, mesmo que os
+// valores das suas filhas se anulem.
+func filtrarContasContabeisFIP215(parametros parametrosRelatorioFIP215, contasContabeis []dadoRelatorioFIP215) []dadoRelatorioFIP215 {
+	mantidas := make([]bool, len(contasContabeis))
+	superioresMantidas := map[string]bool{}
+
+	for i, contaContabil := range contasContabeis {
+		movimento := contaContabil.ValorCredito != 0 || contaContabil.ValorDebito != 0
+		saldo := contaContabil.SaldoAnterior != 0 || contaContabil.SaldoAtual != 0
+
+		mantidas[i] = (!parametros.SomenteMovimento || movimento) && (!parametros.SuprimirZerados || movimento || saldo)
+
+		if !mantidas[i] {
+			continue
+		}
+
+		codigo := strings.ReplaceAll(contaContabil.CodigoContaContabil, ".", "")
+
+		for nivel := 1; nivel < nivelContaContabil(contaContabil.CodigoContaContabil); nivel++ {
+			superioresMantidas[contaContabil.CodigoAgrupamento+"|"+codigo[:min(tamanhoCodigoNivelContaContabil(nivel), len(codigo))]] = true
+		}
+	}
+
+	var filtradas []dadoRelatorioFIP215
+
+	for i, contaContabil := range contasContabeis {
+		prefixo, _ := prefixoContaContabil(contaContabil.CodigoContaContabil)
+
+		if !mantidas[i] && !superioresMantidas[contaContabil.CodigoAgrupamento+"|"+prefixo] {
+			continue
+		}
+
+		if contaContabilSelecionadaFIP215(parametros, contaContabil.CodigoContaContabil) {
+			filtradas = append(filtradas, contaContabil)
+		}
+	}
+
+	return filtradas
+}
+
+// contaContabilSelecionadaFIP215 indica se a conta está dentro dos filtros de
+// nível máximo, intervalo de códigos e prefixo. Os limites do intervalo são
+// completados com zeros (início) e noves (fim) até o tamanho do código.
+func contaContabilSelecionadaFIP215(parametros parametrosRelatorioFIP215, codigoContaContabil string) bool {
+	codigo := strings.ReplaceAll(codigoContaContabil, ".", "")
+
+	if parametros.NivelMaximo != 0 && nivelContaContabil(codigoContaContabil) > parametros.NivelMaximo {
+		return false
+	}
+
+	if parametros.ContaInicio != "" && codigo < completarCodigoContaContabil(parametros.ContaInicio, '0', len(codigo)) {
+		return false
+	}
+
+	if parametros.ContaFim != "" && codigo > completarCodigoContaContabil(parametros.ContaFim, '9', len(codigo)) {
+		return false
+	}
+
+	return strings.HasPrefix(codigo, parametros.PrefixoConta)
+}
+
+func completarCodigoContaContabil(codigo string, digito byte, tamanho int) string {
+	if len(codigo) >= tamanho {
+		return codigo
+	}
+
+	return codigo + strings.Repeat(string(digito), tamanho-len(codigo))
+}
+
+// nivelContaContabil retorna o nível da conta no plano de contas, de 1 (classe)
+// a 8, conforme o último nível preenchido do código.
+func nivelContaContabil(codigoContaContabil string) int {
+	_, indice := prefixoContaContabil(codigoContaContabil)
+
+	return nivelIndiceContaContabil(indice)
+}
+
+// nivelIndiceContaContabil retorna o nível da conta a partir do índice do
+// último dígito do prefixo retornado por prefixoContaContabil.
+func nivelIndiceContaContabil(indice int) int {
+	if indice < 5 {
+		return indice + 1
+	}
+
+	return 6 + (indice-6)/2
+}
+
+// contaContabilIntraOFSS indica se a conta é intra-OFSS, isto é, se é das
+// classes 1 a 4 e o 5º dígito do código (com ou sem pontos) é 2, como no PCASP.
+// Nas classes 5 a 8, o 5º dígito não identifica as contas intra-OFSS.
+func contaContabilIntraOFSS(codigoContaContabil string) bool {
+	codigo := strings.ReplaceAll(codigoContaContabil, ".", "")
+
+	return len(codigo) >= 5 && codigo[0] >= '1' && codigo[0] <= '4' && codigo[4] == '2'
+}
+
+// tamanhoCodigoNivelContaContabil retorna a quantidade de dígitos, sem pontos,
+// do código de uma conta do nível informado.
+func tamanhoCodigoNivelContaContabil(nivel int) int {
+	if nivel <= 5 {
+		return nivel
+	}
+
+	return 5 + (nivel-5)*2
+}
+
+// prefixoContaContabil retorna os dígitos do código da conta contábil, sem
+// pontos, até o seu último nível preenchido, e o índice do último dígito desse
+// nível. Os níveis a partir do sexto têm dois dígitos.
+func prefixoContaContabil(codigoContaContabil string) (string, int) {
+	codigo := strings.ReplaceAll(codigoContaContabil, ".", "")
+	indiceUltimoDigitoNaoZero := 0
+
+	for j, caractere := range codigo {
+		if caractere != '0' {
+			indiceUltimoDigitoNaoZero = j
+		}
+	}
+
+	digitosNaoRelevantes := []int{5, 7, 9}
+	if slices.Contains(digitosNaoRelevantes, indiceUltimoDigitoNaoZero) {
+		indiceUltimoDigitoNaoZero++
+	}
+
+	return codigo[:min(indiceUltimoDigitoNaoZero+1, len(codigo))], indiceUltimoDigitoNaoZero
 }
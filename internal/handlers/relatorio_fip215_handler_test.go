@@ -0,0 +1,54 @@
+package handlers
+
+import "testing"
+
+func TestContaContabilIntraOFSS(t *testing.T) {
+	casos := []struct {
+		codigo string
+		intra  bool
+	}{
+		{"1.1.2.1.2.01.00", true},
+		{"112120100", true},
+		{"3.5.1.1.2.00.00", true},
+		{"4.5.1.1.2.00.00", true},
+		{"1.1.2.1.1.01.00", false},
+		{"4.5.1.1.1.00.00", false},
+		{"6.2.2.1.2.00.00", false},
+		{"8.2.1.1.2.00.00", false},
+		{"5.2.2.1.2.00.00", false},
+		{"1.1.2.1", false},
+		{"", false},
+	}
+
+	for _, caso := range casos {
+		if intra := contaContabilIntraOFSS(caso.codigo); intra != caso.intra {
+			t.Errorf("contaContabilIntraOFSS(%q) = %v, esperado %v", caso.codigo, intra, caso.intra)
+		}
+	}
+}
+
+func TestNivelContaContabil(t *testing.T) {
+	casos := []struct {
+		codigo string
+		nivel  int
+	}{
+		{"1.0.0.0.0.00.00", 1},
+		{"1.1.0.0.0.00.00", 2},
+		{"1.1.1.0.0.00.00", 3},
+		{"1.1.1.1.0.00.00", 4},
+		{"1.1.1.1.1.00.00", 5},
+		{"1.1.1.1.1.01.00", 6},
+		{"1.1.1.1.1.10.00", 6},
+		{"1.1.1.1.1.01.02", 7},
+		{"1.1.1.1.1.01.20", 7},
+		{"1.1.1.1.1.01.02.01", 8},
+		{"111110100", 6},
+		{"2.1.0.0.0.00.00", 2},
+	}
+
+	for _, caso := range casos {
+		if nivel := nivelContaContabil(caso.codigo); nivel != caso.nivel {
+			t.Errorf("nivelContaContabil(%q) = %d, esperado %d", caso.codigo, nivel, caso.nivel)
+		}
+	}
+}
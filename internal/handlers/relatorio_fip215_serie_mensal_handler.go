@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+)
+
+type valorMensalFIP215 struct {
+	Mes           int     `json:"mes"`
+	SaldoAnterior float64 `json:"saldo_anterior"`
+	ValorCredito  float64 `json:"valor_credito"`
+	ValorDebito   float64 `json:"valor_debito"`
+	SaldoAtual    float64 `json:"saldo_atual"`
+} // @name ValorMensalFIP215
+
+type dadoSerieMensalFIP215 struct {
+	CodigoUnidadeOrcamentaria string              `json:"codigo_unidade_orcamentaria"`
+	NomeUnidadeOrcamentaria   string              `json:"nome_unidade_orcamentaria"`
+	IDContaContabil           string              `json:"id_conta_contabil"`
+	CodigoContaContabil       string              `json:"codigo_conta_contabil"`
+	NomeContaContabil         string              `json:"nome_conta_contabil"`
+	Meses                     []valorMensalFIP215 `json:"meses"`
+} // @name DadoSerieMensalFIP215
+
+type relatorioSerieMensalFIP215 struct {
+	Dados []dadoSerieMensalFIP215
+} // @name RelatorioSerieMensalFIP215
+
+// RelatorioFIP215SerieMensalHandler godoc
+//
+// @Summary     FIP215 - Série Mensal do Balancete de Verificação
+// @Description Fornece, em uma única consulta, o saldo anterior, os créditos, os débitos e o saldo atual de cada mês do período para cada conta contábil
+// @Tags        Relatório
+// @Accept      json
+// @Produce     json
+// @Param       ano_exercicio                    query    int    true  "Ano de Exercício"
+// @Param       unidade_gestora                  query    []int  false "Códigos das Unidades Gestoras (separados por vírgula ou com o parâmetro repetido)"              collectionFormat(csv)
+// @Param       unidade_orcamentaria             query    []int  false "Códigos das Unidades Orçamentárias (separados por vírgula ou com o parâmetro repetido)"         collectionFormat(csv)
+// @Param       orgao                            query    []int  false "Códigos dos Órgãos (separados por vírgula ou com o parâmetro repetido)"                         collectionFormat(csv)
+// @Param       por_unidade_orcamentaria         query    bool   false "Separar a Série por Unidade Orçamentária?"                                                      Enums(true, false)
+// @Param       mes_inicio                       query    int    false "Mês de Início da Série (padrão: 1)"                                                             Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_fim                          query    int    false "Mês de Fim da Série (padrão: 12)"                                                               Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_contabil                     query    int    true  "Mês Contábil (1-Execução / 2-Apuração / 3-Encerramento / 4-Todos)"                              Enums(1, 2, 3, 4)
+// @Param       tipo_poder                       query    []int  false "Tipos de Poder (1-Executivo / 2-Legislativo / 3-Judiciário / 4-Ministério Público / 5-Todos)"   collectionFormat(csv) Enums(1, 2, 3, 4, 5)
+// @Param       tipo_administracao               query    int    false "Tipo de Administração (1-Diretas / 2-Indiretas / 3-Todas)"                                      Enums(1, 2, 3)
+// @Param       tipo_encerramento                query    int    false "Tipo de Encerramento (1-Encerra ao Final do Exercício / 2-Transfere para o Exercício Seguinte)" Enums(1, 2)
+// @Param       consolidado_rpps                 query    bool   false "Consolidado RPPS?"                                                                              Enums(true, false)
+// @Param       indicativo_conta_contabil_rp     query    int    false "Indicativo de Conta Contábil de RP"                                                             Enums(1, 2)
+// @Param       indicativo_superavit_fincanceiro query    int    false "Indicativo de Superávit Financeiro"                                                             Enums(1, 2)
+// @Param       indicativo_composicao_msc        query    int    false "Indicativo de Composição da MSC (1-Sim / 2-Não)"                                                Enums(1, 2)
+// @Param       nivel_maximo                     query    int    false "Nível Máximo das Contas (1-Classe / 2-Grupo / 3-Subgrupo / ...)"                                Enums(1, 2, 3, 4, 5, 6, 7, 8)
+// @Param       conta_inicio                     query    string false "Código da Conta Contábil Inicial (sem pontos, completado com zeros)"
+// @Param       conta_fim                        query    string false "Código da Conta Contábil Final (sem pontos, completado com noves)"
+// @Param       prefixo_conta                    query    string false "Prefixo do Código da Conta Contábil (sem pontos)"
+// @Success     200                              {object} relatorioSerieMensalFIP215
+// @Failure     400                              {object} Erro
+// @Failure     500                              {object} Erro
+// @Router      /relatorio/fip_215/serie_mensal [get]
+func RelatorioFIP215SerieMensalHandler(c echo.Context) error {
+	/*** Parâmetros ***/
+	parametros := struct {
+		parametrosRelatorioFIP215
+
+		// Adicionais
+		PorUnidadeOrcamentaria bool
+		MesesExercicio         []string
+	}{}
+	/*** Parâmetros ***/
+
+	/*** Validação dos Parâmetros ***/
+	var erros []string
+
+	parametros.parametrosRelatorioFIP215, erros = lerParametrosRelatorioFIP215(c, false)
+
+	if err := echo.QueryParamsBinder(c).Bool("por_unidade_orcamentaria", &parametros.PorUnidadeOrcamentaria).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça se a série deve ser separada por unidade orçamentária no parâmetro 'por_unidade_orcamentaria'.")
+	}
+
+	// A série traz todas as contas do período, sem agrupamento nem eliminações.
+	erros = append(erros, ParametrosNaoSuportados(c, "agrupamento", "eliminar_intra", "suprimir_zerados", "somente_movimento")...)
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	erros, erro := codigosInexistentesRelatorioFIP215(parametros.parametrosRelatorioFIP215)
+
+	if erro != nil {
+		return erro
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	for i := 1; i <= 12; i++ {
+		parametros.MesesExercicio = append(parametros.MesesExercicio, MesParaNome[i])
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	var serieMensal relatorioSerieMensalFIP215
+
+	contasContabeisSinteticas, erro := consultarContasContabeisSinteticasFIP215(parametros.AnoExercicio)
+
+	if erro != nil {
+		return erro
+	}
+
+	queryTemplate := `SELECT
+	SERIE.CD_UNIDADE_ORCAMENTARIA,
+	SERIE.DS_UNIDADE_ORCAMENTARIA,
+	SERIE.IDEN_CONTA_CONTABIL,
+	SERIE.CODG_CONTA_CONTABIL,
+	SERIE.NOME_CONTA_CONTABIL,
+	COALESCE(SUM(SI.SALDO_ABERTURA), 0) AS SALDO_ABERTURA,
+
+	{{range $i, $mes := .MesesExercicio}}
+	{{if $i}},{{end}}
+	SUM(SERIE.VALR_CRE_{{$mes}}) AS VALR_CRE_{{$mes}},
+	SUM(SERIE.VALR_DEB_{{$mes}}) AS VALR_DEB_{{$mes}},
+	SUM(SERIE.VALR_{{$mes}}) AS VALR_{{$mes}}
+	{{end}}
+
+	FROM
+	(
+		SELECT
+		UO.CD_EXERCICIO,
+		UG.ID_UNIDADE_GESTORA,
+
+		{{if .PorUnidadeOrcamentaria}}
+		TO_CHAR(UO.CD_UNIDADE_ORCAMENTARIA) AS CD_UNIDADE_ORCAMENTARIA,
+		UO.DS_UNIDADE_ORCAMENTARIA,
+		{{else}}
+		' ' AS CD_UNIDADE_ORCAMENTARIA,
+		' ' AS DS_UNIDADE_ORCAMENTARIA,
+		{{end}}
+
+		CC.IDEN_CONTA_CONTABIL,
+		CC.CODG_CONTA_CONTABIL,
+		CC.NOME_CONTA_CONTABIL,
+
+		{{range $i, $mes := .MesesExercicio}}
+		{{if $i}},{{end}}
+		SUM(NVL(SM.VALR_CRE_{{$mes}}, 0)) AS VALR_CRE_{{$mes}},
+		SUM(NVL(SM.VALR_DEB_{{$mes}}, 0)) AS VALR_DEB_{{$mes}},
+		SUM(NVL(SM.VALR_{{$mes}}, 0)) AS VALR_{{$mes}}
+		{{end}}
+
+		FROM
+		{{template "juncoesFIP215" .}}
+
+		{{template "filtrosFIP215" .}}
+
+		GROUP BY
+		UO.CD_EXERCICIO,
+		UG.ID_UNIDADE_GESTORA,
+
+		{{if .PorUnidadeOrcamentaria}}
+		UO.CD_UNIDADE_ORCAMENTARIA,
+		UO.DS_UNIDADE_ORCAMENTARIA,
+		{{end}}
+
+		CC.IDEN_CONTA_CONTABIL,
+		CC.CODG_CONTA_CONTABIL,
+		CC.NOME_CONTA_CONTABIL
+	) SERIE
+	LEFT JOIN
+	(
+		SELECT
+		CD_EXERCICIO,
+		ID_UNIDADE_GESTORA,
+		IDEN_CONTA_CONTABIL,
+		SUM(SALDO_ABERTURA) AS SALDO_ABERTURA
+
+		FROM ACWTB0197
+
+		GROUP BY
+		CD_EXERCICIO,
+		ID_UNIDADE_GESTORA,
+		IDEN_CONTA_CONTABIL
+	) SI ON SI.CD_EXERCICIO = SERIE.CD_EXERCICIO
+	AND SI.ID_UNIDADE_GESTORA = SERIE.ID_UNIDADE_GESTORA
+	AND SI.IDEN_CONTA_CONTABIL = SERIE.IDEN_CONTA_CONTABIL
+
+	GROUP BY
+	SERIE.CD_UNIDADE_ORCAMENTARIA,
+	SERIE.DS_UNIDADE_ORCAMENTARIA,
+	SERIE.IDEN_CONTA_CONTABIL,
+	SERIE.CODG_CONTA_CONTABIL,
+	SERIE.NOME_CONTA_CONTABIL`
+
+	compactSqlQuery, erro := montarQueryFIP215("queryMain", queryTemplate, parametros)
+
+	if erro != nil {
+		return erro
+	}
+
+	log.Printf("RelatorioFIP215SerieMensalHandler: %s", compactSqlQuery)
+	rows, err := Db.Query(compactSqlQuery)
+
+	if err != nil {
+		log.Printf("RelatorioFIP215SerieMensalHandler: %v", err)
+		return ErroConsultaBancoDados
+	}
+
+	defer rows.Close()
+
+	var serieMensalEspecifica []dadoSerieMensalFIP215
+
+	for rows.Next() {
+		var dado dadoSerieMensalFIP215
+		var saldoAbertura float64
+
+		// Créditos, débitos e saldos acumulados de cada mês, com o índice 0
+		// reservado para o saldo antes de janeiro.
+		var creditos, debitos, saldos [13]float64
+
+		destinos := []any{
+			&dado.CodigoUnidadeOrcamentaria,
+			&dado.NomeUnidadeOrcamentaria,
+			&dado.IDContaContabil,
+			&dado.CodigoContaContabil,
+			&dado.NomeContaContabil,
+			&saldoAbertura,
+		}
+
+		for mes := 1; mes <= 12; mes++ {
+			destinos = append(destinos, &creditos[mes], &debitos[mes], &saldos[mes])
+		}
+
+		if err := rows.Scan(destinos...); err != nil {
+			log.Printf("RelatorioFIP215SerieMensalHandler: %v", err)
+			return ErroConsultaLinhaBancoDados
+		}
+
+		if dado.CodigoUnidadeOrcamentaria == " " {
+			dado.CodigoUnidadeOrcamentaria = ""
+			dado.NomeUnidadeOrcamentaria = ""
+		}
+
+		dado.Meses = valoresMensaisFIP215(saldoAbertura, creditos, debitos, saldos, parametros.MesInicio, parametros.MesFim)
+
+		serieMensalEspecifica = append(serieMensalEspecifica, dado)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("RelatorioFIP215SerieMensalHandler: %v", err)
+		return ErroRedeOuResultadoBancoDados
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	/*** Lógica Adicional ***/
+	serieMensal.Dados = totalizarSerieMensalFIP215(contasContabeisSinteticas, serieMensalEspecifica)
+
+	for i := range serieMensal.Dados {
+		for m := range serieMensal.Dados[i].Meses {
+			valor := &serieMensal.Dados[i].Meses[m]
+			valor.SaldoAnterior = math.Round(valor.SaldoAnterior*100.0) / 100.0
+			valor.ValorCredito = math.Round(valor.ValorCredito*100.0) / 100.0
+			valor.ValorDebito = math.Round(valor.ValorDebito*100.0) / 100.0
+			valor.SaldoAtual = math.Round(valor.SaldoAtual*100.0) / 100.0
+		}
+	}
+
+	var serieMensalFiltrada []dadoSerieMensalFIP215
+
+	for _, contaContabil := range serieMensal.Dados {
+		if contaContabilSelecionadaFIP215(parametros.parametrosRelatorioFIP215, contaContabil.CodigoContaContabil) {
+			serieMensalFiltrada = append(serieMensalFiltrada, contaContabil)
+		}
+	}
+
+	serieMensal.Dados = serieMensalFiltrada
+
+	sort.Slice(serieMensal.Dados, func(i, j int) bool {
+		if serieMensal.Dados[i].CodigoContaContabil != serieMensal.Dados[j].CodigoContaContabil {
+			return serieMensal.Dados[i].CodigoContaContabil < serieMensal.Dados[j].CodigoContaContabil
+		}
+
+		return serieMensal.Dados[i].CodigoUnidadeOrcamentaria < serieMensal.Dados[j].CodigoUnidadeOrcamentaria
+	})
+	/*** Lógica Adicional ***/
+
+	return c.JSON(http.StatusOK, serieMensal)
+}
+
+// valoresMensaisFIP215 calcula os valores de cada mês de mesInicio a mesFim a
+// partir do saldo de abertura e dos créditos, débitos e saldos acumulados de
+// cada mês, com o índice 0 reservado para o saldo antes de janeiro.
+func valoresMensaisFIP215(saldoAbertura float64, creditos, debitos, saldos [13]float64, mesInicio, mesFim int) []valorMensalFIP215 {
+	var valores []valorMensalFIP215
+
+	for mes := mesInicio; mes <= mesFim; mes++ {
+		valor := valorMensalFIP215{
+			Mes:           mes,
+			SaldoAnterior: saldoAbertura - saldos[mes-1],
+			ValorCredito:  creditos[mes],
+			ValorDebito:   debitos[mes],
+		}
+
+		valor.SaldoAtual = valor.ValorCredito - valor.ValorDebito + valor.SaldoAnterior
+
+		valores = append(valores, valor)
+	}
+
+	return valores
+}
+
+// totalizarSerieMensalFIP215 retorna as séries das contas sintéticas,
+// totalizadas a partir das séries das contas analíticas, seguidas das séries
+// das contas analíticas.
+func totalizarSerieMensalFIP215(contasContabeisSinteticas []dadoRelatorioFIP215, contasContabeisAnaliticas []dadoSerieMensalFIP215) []dadoSerieMensalFIP215 {
+	var dados []dadoSerieMensalFIP215
+
+	// Cada unidade orçamentária recebe cópias das contas sintéticas superiores
+	// às suas contas analíticas, que totalizam apenas as analíticas da unidade.
+	indicesSinteticas := make(map[string]int, len(contasContabeisSinteticas))
+
+	for i, contaContabil := range contasContabeisSinteticas {
+		prefixo, _ := prefixoContaContabil(contaContabil.CodigoContaContabil)
+		indicesSinteticas[prefixo] = i
+	}
+
+	indicesSeries := map[string]int{}
+
+	for _, dado := range contasContabeisAnaliticas {
+		codigo, indice := prefixoContaContabil(dado.CodigoContaContabil)
+
+		for nivel := nivelIndiceContaContabil(indice) - 1; nivel >= 1; nivel-- {
+			prefixo := codigo[:min(tamanhoCodigoNivelContaContabil(nivel), len(codigo))]
+			i, ok := indicesSinteticas[prefixo]
+
+			if !ok {
+				continue
+			}
+
+			chave := dado.CodigoUnidadeOrcamentaria + "|" + prefixo
+			j, ok := indicesSeries[chave]
+
+			if !ok {
+				j = len(dados)
+				indicesSeries[chave] = j
+
+				sintetica := dadoSerieMensalFIP215{
+					CodigoUnidadeOrcamentaria: dado.CodigoUnidadeOrcamentaria,
+					NomeUnidadeOrcamentaria:   dado.NomeUnidadeOrcamentaria,
+					IDContaContabil:           contasContabeisSinteticas[i].IDContaContabil,
+					CodigoContaContabil:       contasContabeisSinteticas[i].CodigoContaContabil,
+					NomeContaContabil:         contasContabeisSinteticas[i].NomeContaContabil,
+				}
+
+				for _, valor := range dado.Meses {
+					sintetica.Meses = append(sintetica.Meses, valorMensalFIP215{Mes: valor.Mes})
+				}
+
+				dados = append(dados, sintetica)
+			}
+
+			for m, valor := range dado.Meses {
+				dados[j].Meses[m].SaldoAnterior += valor.SaldoAnterior
+				dados[j].Meses[m].ValorCredito += valor.ValorCredito
+				dados[j].Meses[m].ValorDebito += valor.ValorDebito
+				dados[j].Meses[m].SaldoAtual += valor.SaldoAtual
+			}
+		}
+	}
+
+	return append(dados, contasContabeisAnaliticas...)
+}
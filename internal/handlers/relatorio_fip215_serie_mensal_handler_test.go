@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValoresMensaisFIP215(t *testing.T) {
+	// Saldo devedor de abertura de 100, com os saldos acumulados de cada mês
+	// (débitos menos créditos desde janeiro).
+	creditos := [13]float64{0, 10, 5, 0}
+	debitos := [13]float64{0, 30, 0, 40}
+	saldos := [13]float64{0, 20, 15, 55}
+
+	casos := []struct {
+		nome      string
+		mesInicio int
+		mesFim    int
+		esperados []valorMensalFIP215
+	}{
+		{
+			nome:      "série desde janeiro",
+			mesInicio: 1,
+			mesFim:    3,
+			esperados: []valorMensalFIP215{
+				{Mes: 1, SaldoAnterior: -100, ValorCredito: 10, ValorDebito: 30, SaldoAtual: -120},
+				{Mes: 2, SaldoAnterior: -120, ValorCredito: 5, ValorDebito: 0, SaldoAtual: -115},
+				{Mes: 3, SaldoAnterior: -115, ValorCredito: 0, ValorDebito: 40, SaldoAtual: -155},
+			},
+		},
+		{
+			nome:      "série iniciada depois de janeiro",
+			mesInicio: 2,
+			mesFim:    2,
+			esperados: []valorMensalFIP215{
+				{Mes: 2, SaldoAnterior: -120, ValorCredito: 5, ValorDebito: 0, SaldoAtual: -115},
+			},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			if valores := valoresMensaisFIP215(-100, creditos, debitos, saldos, caso.mesInicio, caso.mesFim); !reflect.DeepEqual(valores, caso.esperados) {
+				t.Errorf("valores = %+v, esperado %+v", valores, caso.esperados)
+			}
+		})
+	}
+}
+
+func TestTotalizarSerieMensalFIP215(t *testing.T) {
+	sinteticas := []dadoRelatorioFIP215{
+		{CodigoContaContabil: "1.0.0.0.0.00.00", NomeContaContabil: "ATIVO"},
+		{CodigoContaContabil: "1.1.0.0.0.00.00", NomeContaContabil: "ATIVO CIRCULANTE"},
+		{CodigoContaContabil: "1.1.1.0.0.00.00", NomeContaContabil: "CAIXA E EQUIVALENTES"},
+	}
+
+	serie := func(uo, conta string, credito, debito float64) dadoSerieMensalFIP215 {
+		return dadoSerieMensalFIP215{
+			CodigoUnidadeOrcamentaria: uo,
+			CodigoContaContabil:       conta,
+			Meses: []valorMensalFIP215{
+				{Mes: 1, SaldoAnterior: -10, ValorCredito: credito, ValorDebito: debito, SaldoAtual: credito - debito - 10},
+				{Mes: 2, SaldoAnterior: credito - debito - 10, SaldoAtual: credito - debito - 10},
+			},
+		}
+	}
+
+	casos := []struct {
+		nome       string
+		analiticas []dadoSerieMensalFIP215
+		esperados  []dadoSerieMensalFIP215
+	}{
+		{
+			nome:      "sem contas analíticas",
+			esperados: nil,
+		},
+		{
+			nome: "soma as analíticas em cada sintética superior existente",
+			analiticas: []dadoSerieMensalFIP215{
+				serie("", "1.1.1.1.1.01.00", 5, 20),
+				serie("", "1.1.2.1.1.01.00", 0, 1),
+			},
+			esperados: []dadoSerieMensalFIP215{
+				{CodigoContaContabil: "1.1.1.0.0.00.00", NomeContaContabil: "CAIXA E EQUIVALENTES", Meses: []valorMensalFIP215{{Mes: 1, SaldoAnterior: -10, ValorCredito: 5, ValorDebito: 20, SaldoAtual: -25}, {Mes: 2, SaldoAnterior: -25, SaldoAtual: -25}}},
+				{CodigoContaContabil: "1.1.0.0.0.00.00", NomeContaContabil: "ATIVO CIRCULANTE", Meses: []valorMensalFIP215{{Mes: 1, SaldoAnterior: -20, ValorCredito: 5, ValorDebito: 21, SaldoAtual: -36}, {Mes: 2, SaldoAnterior: -36, SaldoAtual: -36}}},
+				{CodigoContaContabil: "1.0.0.0.0.00.00", NomeContaContabil: "ATIVO", Meses: []valorMensalFIP215{{Mes: 1, SaldoAnterior: -20, ValorCredito: 5, ValorDebito: 21, SaldoAtual: -36}, {Mes: 2, SaldoAnterior: -36, SaldoAtual: -36}}},
+				serie("", "1.1.1.1.1.01.00", 5, 20),
+				serie("", "1.1.2.1.1.01.00", 0, 1),
+			},
+		},
+		{
+			nome: "totaliza cada unidade orçamentária separadamente",
+			analiticas: []dadoSerieMensalFIP215{
+				serie("10", "1.1.1.1.1.01.00", 5, 20),
+				serie("20", "1.1.1.1.1.01.00", 3, 0),
+			},
+			esperados: []dadoSerieMensalFIP215{
+				{CodigoUnidadeOrcamentaria: "10", CodigoContaContabil: "1.1.1.0.0.00.00", NomeContaContabil: "CAIXA E EQUIVALENTES", Meses: []valorMensalFIP215{{Mes: 1, SaldoAnterior: -10, ValorCredito: 5, ValorDebito: 20, SaldoAtual: -25}, {Mes: 2, SaldoAnterior: -25, SaldoAtual: -25}}},
+				{CodigoUnidadeOrcamentaria: "10", CodigoContaContabil: "1.1.0.0.0.00.00", NomeContaContabil: "ATIVO CIRCULANTE", Meses: []valorMensalFIP215{{Mes: 1, SaldoAnterior: -10, ValorCredito: 5, ValorDebito: 20, SaldoAtual: -25}, {Mes: 2, SaldoAnterior: -25, SaldoAtual: -25}}},
+				{CodigoUnidadeOrcamentaria: "10", CodigoContaContabil: "1.0.0.0.0.00.00", NomeContaContabil: "ATIVO", Meses: []valorMensalFIP215{{Mes: 1, SaldoAnterior: -10, ValorCredito: 5, ValorDebito: 20, SaldoAtual: -25}, {Mes: 2, SaldoAnterior: -25, SaldoAtual: -25}}},
+				{CodigoUnidadeOrcamentaria: "20", CodigoContaContabil: "1.1.1.0.0.00.00", NomeContaContabil: "CAIXA E EQUIVALENTES", Meses: []valorMensalFIP215{{Mes: 1, SaldoAnterior: -10, ValorCredito: 3, SaldoAtual: -7}, {Mes: 2, SaldoAnterior: -7, SaldoAtual: -7}}},
+				{CodigoUnidadeOrcamentaria: "20", CodigoContaContabil: "1.1.0.0.0.00.00", NomeContaContabil: "ATIVO CIRCULANTE", Meses: []valorMensalFIP215{{Mes: 1, SaldoAnterior: -10, ValorCredito: 3, SaldoAtual: -7}, {Mes: 2, SaldoAnterior: -7, SaldoAtual: -7}}},
+				{CodigoUnidadeOrcamentaria: "20", CodigoContaContabil: "1.0.0.0.0.00.00", NomeContaContabil: "ATIVO", Meses: []valorMensalFIP215{{Mes: 1, SaldoAnterior: -10, ValorCredito: 3, SaldoAtual: -7}, {Mes: 2, SaldoAnterior: -7, SaldoAtual: -7}}},
+				serie("10", "1.1.1.1.1.01.00", 5, 20),
+				serie("20", "1.1.1.1.1.01.00", 3, 0),
+			},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			if dados := totalizarSerieMensalFIP215(sinteticas, caso.analiticas); !reflect.DeepEqual(dados, caso.esperados) {
+				t.Errorf("séries = %+v, esperado %+v", dados, caso.esperados)
+			}
+		})
+	}
+}
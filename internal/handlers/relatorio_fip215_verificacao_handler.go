@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type violacaoVerificacaoFIP215 struct {
+	Verificacao     string   `json:"verificacao"`
+	Descricao       string   `json:"descricao"`
+	Diferenca       float64  `json:"diferenca"`
+	ContasContabeis []string `json:"contas_contabeis"`
+} // @name ViolacaoVerificacaoFIP215
+
+type relatorioVerificacaoFIP215 struct {
+	Consistente bool
+	Violacoes   []violacaoVerificacaoFIP215
+} // @name RelatorioVerificacaoFIP215
+
+/*** Dados Estáticos ***/
+// Diferença máxima aceita entre os valores comparados, para absorver o
+// arredondamento dos centavos.
+const toleranciaVerificacaoFIP215 = 0.01
+
+/*** Dados Estáticos ***/
+
+// RelatorioFIP215VerificacaoHandler godoc
+//
+// @Summary     FIP215 - Verificação de Consistência do Balancete
+// @Description Executa o balancete consolidado e verifica se os débitos igualam os créditos, se o ativo iguala o passivo mais o patrimônio líquido e o resultado do período, e se as classes de controle 5 e 6 e 7 e 8 se equilibram. Não verifica se as contas sintéticas somam as analíticas: o balancete calcula as sintéticas a partir das analíticas, e esta API não consulta outra fonte de saldos sintéticos com que compará-las
+// @Tags        Relatório
+// @Accept      json
+// @Produce     json
+// @Param       ano_exercicio        query    int   true  "Ano de Exercício"
+// @Param       unidade_gestora      query    []int false "Códigos das Unidades Gestoras (separados por vírgula ou com o parâmetro repetido)"            collectionFormat(csv)
+// @Param       unidade_orcamentaria query    []int false "Códigos das Unidades Orçamentárias (separados por vírgula ou com o parâmetro repetido)"       collectionFormat(csv)
+// @Param       orgao                query    []int false "Códigos dos Órgãos (separados por vírgula ou com o parâmetro repetido)"                       collectionFormat(csv)
+// @Param       mes_referencia       query    int   false "Mês de Referência"                                                                            Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       ate_mes_referencia   query    bool  false "Contabilizar do Início do Exercício até o Mês de Referência?"                                 Enums(true, false)
+// @Param       mes_inicio           query    int   false "Mês de Início do Período (substitui o mês de referência)"                                     Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_fim              query    int   false "Mês de Fim do Período (substitui o mês de referência)"                                        Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_contabil         query    int   true  "Mês Contábil (1-Execução / 2-Apuração / 3-Encerramento / 4-Todos)"                            Enums(1, 2, 3, 4)
+// @Param       tipo_poder           query    []int false "Tipos de Poder (1-Executivo / 2-Legislativo / 3-Judiciário / 4-Ministério Público / 5-Todos)" collectionFormat(csv) Enums(1, 2, 3, 4, 5)
+// @Param       tipo_administracao   query    int   false "Tipo de Administração (1-Diretas / 2-Indiretas / 3-Todas)"                                    Enums(1, 2, 3)
+// @Param       consolidado_rpps     query    bool  false "Consolidado RPPS?"                                                                            Enums(true, false)
+// @Success     200                  {object} relatorioVerificacaoFIP215
+// @Failure     400                  {object} Erro
+// @Failure     500                  {object} Erro
+// @Router      /relatorio/fip_215/verificacao [get]
+func RelatorioFIP215VerificacaoHandler(c echo.Context) error {
+	/*** Validação dos Parâmetros ***/
+	parametros, erros := lerParametrosRelatorioFIP215(c, true)
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	erros, erro := codigosInexistentesRelatorioFIP215(parametros)
+
+	if erro != nil {
+		return erro
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	// As verificações precisam de todas as contas do plano, então os filtros
+	// de contas e o agrupamento do balancete não se aplicam.
+	erros = ParametrosNaoSuportados(c, "agrupamento", "nivel_maximo", "conta_inicio", "conta_fim", "prefixo_conta", "suprimir_zerados", "somente_movimento", "tipo_encerramento", "indicativo_conta_contabil_rp", "indicativo_superavit_fincanceiro", "indicativo_composicao_msc")
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	parametros.Agrupamento = "consolidado"
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	balancete, erro := consultarRelatorioFIP215(parametros)
+
+	if erro != nil {
+		return erro
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	/*** Lógica Adicional ***/
+	var verificacao relatorioVerificacaoFIP215
+
+	var totalDebito, totalCredito float64
+	saldoClasse := map[byte]float64{}
+	contasClasse := map[byte]string{}
+
+	for _, contaContabil := range balancete.Dados {
+		codigo := strings.ReplaceAll(contaContabil.CodigoContaContabil, ".", "")
+
+		if codigo == "" {
+			continue
+		}
+
+		if nivelContaContabil(contaContabil.CodigoContaContabil) == 1 {
+			contasClasse[codigo[0]] = contaContabil.CodigoContaContabil
+		}
+
+		if !contaContabil.ContaEscrituravel {
+			continue
+		}
+
+		totalDebito += contaContabil.ValorDebito
+		totalCredito += contaContabil.ValorCredito
+		saldoClasse[codigo[0]] += contaContabil.SaldoAtual
+	}
+
+	contasDasClasses := func(classes ...byte) []string {
+		var contas []string
+
+		for _, classe := range classes {
+			if conta, ok := contasClasse[classe]; ok {
+				contas = append(contas, conta)
+			} else {
+				contas = append(contas, string(classe))
+			}
+		}
+
+		return contas
+	}
+
+	adicionarViolacao := func(nome string, descricao string, diferenca float64, contasContabeis []string) {
+		if math.Abs(diferenca) <= toleranciaVerificacaoFIP215 {
+			return
+		}
+
+		verificacao.Violacoes = append(verificacao.Violacoes, violacaoVerificacaoFIP215{
+			Verificacao:     nome,
+			Descricao:       descricao,
+			Diferenca:       math.Round(diferenca*100.0) / 100.0,
+			ContasContabeis: contasContabeis,
+		})
+	}
+
+	adicionarViolacao(
+		"DEBITOS_IGUAIS_CREDITOS",
+		fmt.Sprintf("O total de débitos (%.2f) difere do total de créditos (%.2f) das contas escrituráveis.", totalDebito, totalCredito),
+		totalDebito-totalCredito,
+		contasDasClasses('1', '2', '3', '4', '5', '6', '7', '8'),
+	)
+
+	// Antes do encerramento, o resultado do período (classes 3 e 4) ainda não
+	// foi transferido para o patrimônio líquido e entra na equação.
+	adicionarViolacao(
+		"ATIVO_IGUAL_PASSIVO_MAIS_PL",
+		"O saldo do ativo (classe 1) difere do saldo do passivo e patrimônio líquido (classe 2) somado ao resultado do período (classes 3 e 4).",
+		saldoClasse['1']+saldoClasse['2']+saldoClasse['3']+saldoClasse['4'],
+		contasDasClasses('1', '2', '3', '4'),
+	)
+
+	adicionarViolacao(
+		"CONTROLES_ORCAMENTARIOS",
+		"O saldo dos controles da aprovação do planejamento e orçamento (classe 5) difere do saldo dos controles da sua execução (classe 6).",
+		saldoClasse['5']+saldoClasse['6'],
+		contasDasClasses('5', '6'),
+	)
+
+	adicionarViolacao(
+		"CONTROLES_DEVEDORES_CREDORES",
+		"O saldo dos controles devedores (classe 7) difere do saldo dos controles credores (classe 8).",
+		saldoClasse['7']+saldoClasse['8'],
+		contasDasClasses('7', '8'),
+	)
+
+	verificacao.Consistente = len(verificacao.Violacoes) == 0
+	/*** Lógica Adicional ***/
+
+	return c.JSON(http.StatusOK, verificacao)
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+)
+
+type diferencaContaConciliacaoFIP215M struct {
+	CodigoContaSICONFI  string   `json:"codigo_conta_siconfi"`
+	ContasContabeis     []string `json:"contas_contabeis"`
+	ValorCreditoFIP215  float64  `json:"valor_credito_fip215"`
+	ValorDebitoFIP215   float64  `json:"valor_debito_fip215"`
+	SaldoAtualFIP215    float64  `json:"saldo_atual_fip215"`
+	ValorCreditoFIP215M float64  `json:"valor_credito_fip215m"`
+	ValorDebitoFIP215M  float64  `json:"valor_debito_fip215m"`
+	SaldoAtualFIP215M   float64  `json:"saldo_atual_fip215m"`
+	DiferencaCredito    float64  `json:"diferenca_credito"`
+	DiferencaDebito     float64  `json:"diferenca_debito"`
+	DiferencaSaldoAtual float64  `json:"diferenca_saldo_atual"`
+} // @name DiferencaContaConciliacaoFIP215M
+
+type conciliacaoPoderOrgaoFIP215M struct {
+	CodigoPoderOrgao         int                                `json:"codigo_poder_orgao"`
+	Conciliado               bool                               `json:"conciliado"`
+	Diferencas               []diferencaContaConciliacaoFIP215M `json:"diferencas"`
+	ContasSemMapeamento      []string                           `json:"contas_sem_mapeamento"`
+	ContasSemMapeamentoUnico []string                           `json:"contas_sem_mapeamento_unico"`
+} // @name ConciliacaoPoderOrgaoFIP215M
+
+type relatorioConciliacaoFIP215M struct {
+	Conciliado                 bool
+	PoderesOrgaos              []conciliacaoPoderOrgaoFIP215M
+	PoderesOrgaosSemAssociacao []int
+} // @name RelatorioConciliacaoFIP215M
+
+// RelatorioFIP215MConciliacaoHandler godoc
+//
+// @Summary     FIP215M - Conciliação entre o Balancete (FIP215) e a MSC (FIP215M)
+// @Description Agrega as contas analíticas do FIP215 nas contas SICONFI pelo mapeamento do exercício e compara os créditos, os débitos e o saldo atual do mês com os da MSC, para o consolidado do estado (código 0) e para cada poder/órgão SICONFI do exercício (ACWTB0803) associado às unidades do FIPLAN nas regras do exercício, retornando apenas as contas com diferença, as contas sem mapeamento, as contas mapeadas em mais de uma conta SICONFI e os poderes/órgãos sem associação, que impedem a conciliação
+// @Tags        Relatório
+// @Accept      json
+// @Produce     json
+// @Param       ano_exercicio      query    int true "Ano de Exercício"
+// @Param       mes_referencia     query    int true "Mês de Referência"                                                                Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_contabil       query    int true "Mês Contábil (1-Execução / 2-Apuração / 3-Todos)"                                 Enums(1, 2, 3)
+// @Param       codigo_poder_orgao query    int true "Código do Poder/Órgão SICONFI (0 para o consolidado e todos os poderes/órgãos)"
+// @Success     200                {object} relatorioConciliacaoFIP215M
+// @Failure     400                {object} Erro
+// @Failure     500                {object} Erro
+// @Router      /relatorio/fip_215m/conciliacao [get]
+func RelatorioFIP215MConciliacaoHandler(c echo.Context) error {
+	/*** Validação dos Parâmetros ***/
+	parametros, erros := lerParametrosRelatorioFIP215M(c)
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	erros, erro := poderOrgaoInexistenteFIP215M(parametros)
+
+	if erro != nil {
+		return erro
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	poderesOrgaosSICONFI, erro := consultarPoderesOrgaosSICONFI(parametros.AnoExercicio)
+
+	if erro != nil {
+		return erro
+	}
+
+	// Os poderes/órgãos vêm da ACWTB0803; as regras do exercício só associam
+	// cada um às unidades do FIPLAN.
+	regras, _ := RegrasDoExercicio(parametros.AnoExercicio)
+
+	associacoes := map[int]associacaoPoderOrgaoSICONFI{}
+
+	for _, associacao := range regras.PoderesOrgaosSICONFI {
+		associacoes[associacao.CodigoPoderOrgao] = associacao
+	}
+
+	var conciliacao relatorioConciliacaoFIP215M
+
+	var poderesOrgaos []associacaoPoderOrgaoSICONFI
+
+	// O consolidado do estado não tem filtros no FIP215.
+	if parametros.CodigoPoderOrgao == 0 {
+		poderesOrgaos = append(poderesOrgaos, associacaoPoderOrgaoSICONFI{})
+	}
+
+	for _, poderOrgao := range poderesOrgaosSICONFI {
+		if parametros.CodigoPoderOrgao != 0 && poderOrgao.Codigo != parametros.CodigoPoderOrgao {
+			continue
+		}
+
+		if associacao, ok := associacoes[poderOrgao.Codigo]; ok {
+			poderesOrgaos = append(poderesOrgaos, associacao)
+		} else {
+			conciliacao.PoderesOrgaosSemAssociacao = append(conciliacao.PoderesOrgaosSemAssociacao, poderOrgao.Codigo)
+		}
+	}
+
+	if parametros.CodigoPoderOrgao != 0 && len(poderesOrgaos) == 0 {
+		return ErroValidacaoParametro([]string{fmt.Sprintf("O poder/órgão SICONFI %d não está associado às unidades do FIPLAN nas regras do exercício %d.", parametros.CodigoPoderOrgao, parametros.AnoExercicio)})
+	}
+
+	// O mês contábil 3 (Todos) da MSC corresponde ao mês contábil 4 (Todos) do
+	// FIP215, que desconsidera a abertura.
+	mesContabil := map[int]int{1: 1, 2: 2, 3: 4}[parametros.MesContabil]
+
+	var parametrosFIP215 []parametrosRelatorioFIP215
+
+	for _, poderOrgao := range poderesOrgaos {
+		filtros := parametrosRelatorioFIP215{
+			AnoExercicio:          parametros.AnoExercicio,
+			Agrupamento:           "consolidado",
+			TiposPoder:            poderOrgao.TiposPoder,
+			Orgaos:                poderOrgao.Orgaos,
+			UnidadesOrcamentarias: poderOrgao.UnidadesOrcamentarias,
+		}
+
+		filtros.Regras, erros = regrasRelatorioFIP215(filtros, parametros.AnoExercicio)
+
+		if len(erros) > 0 {
+			return ErroValidacaoParametro(erros)
+		}
+
+		erros, erro = codigosInexistentesRelatorioFIP215(filtros)
+
+		if erro != nil {
+			return erro
+		}
+
+		if len(erros) > 0 {
+			return ErroValidacaoParametro(erros)
+		}
+
+		parametrosFIP215 = append(parametrosFIP215, periodoBalanceteDemonstrativo(filtros, parametros.AnoExercicio, parametros.MesReferencia, parametros.MesReferencia, mesContabil))
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	mapeamento, erro := consultarMapeamentoContasSICONFI(parametros.AnoExercicio)
+
+	if erro != nil {
+		return erro
+	}
+
+	// Uma conta contábil mapeada em mais de uma conta SICONFI não tem como
+	// ter o seu valor atribuído, e é listada à parte.
+	contasSICONFI := map[string][]string{}
+
+	for _, conta := range mapeamento {
+		if !slices.Contains(contasSICONFI[conta.CodigoContaContabil], conta.CodigoContaSICONFI) {
+			contasSICONFI[conta.CodigoContaContabil] = append(contasSICONFI[conta.CodigoContaContabil], conta.CodigoContaSICONFI)
+		}
+	}
+
+	for i, poderOrgao := range poderesOrgaos {
+		balancete, erro := consultarRelatorioFIP215(parametrosFIP215[i])
+
+		if erro != nil {
+			return erro
+		}
+
+		parametros.CodigoPoderOrgao = poderOrgao.CodigoPoderOrgao
+
+		msc, erro := consultarRelatorioFIP215M(parametros)
+
+		if erro != nil {
+			return erro
+		}
+
+		resultado := conciliacaoPoderOrgaoFIP215M{CodigoPoderOrgao: poderOrgao.CodigoPoderOrgao}
+		contas := map[string]*diferencaContaConciliacaoFIP215M{}
+
+		contaConciliacao := func(codigoContaSICONFI string) *diferencaContaConciliacaoFIP215M {
+			if _, ok := contas[codigoContaSICONFI]; !ok {
+				contas[codigoContaSICONFI] = &diferencaContaConciliacaoFIP215M{CodigoContaSICONFI: codigoContaSICONFI}
+			}
+
+			return contas[codigoContaSICONFI]
+		}
+
+		for _, contaContabil := range balancete.Dados {
+			if !contaContabil.ContaEscrituravel {
+				continue
+			}
+
+			codigosContasSICONFI := contasSICONFI[contaContabil.CodigoContaContabil]
+
+			if len(codigosContasSICONFI) != 1 {
+				if contaContabil.ValorCredito != 0 || contaContabil.ValorDebito != 0 || contaContabil.SaldoAtual != 0 {
+					if len(codigosContasSICONFI) == 0 {
+						resultado.ContasSemMapeamento = append(resultado.ContasSemMapeamento, contaContabil.CodigoContaContabil)
+					} else {
+						resultado.ContasSemMapeamentoUnico = append(resultado.ContasSemMapeamentoUnico, contaContabil.CodigoContaContabil)
+					}
+				}
+
+				continue
+			}
+
+			conta := contaConciliacao(codigosContasSICONFI[0])
+			conta.ContasContabeis = append(conta.ContasContabeis, contaContabil.CodigoContaContabil)
+			conta.ValorCreditoFIP215 += contaContabil.ValorCredito
+			conta.ValorDebitoFIP215 += contaContabil.ValorDebito
+			conta.SaldoAtualFIP215 += contaContabil.SaldoAtual
+		}
+
+		for _, dado := range msc.Dados {
+			conta := contaConciliacao(dado.CodigoContaSICONFI)
+			conta.ValorCreditoFIP215M += dado.ValorCredito
+			conta.ValorDebitoFIP215M += dado.ValorDebito
+			conta.SaldoAtualFIP215M += dado.SaldoAtual
+		}
+
+		for _, conta := range contas {
+			conta.ValorCreditoFIP215 = math.Round(conta.ValorCreditoFIP215*100.0) / 100.0
+			conta.ValorDebitoFIP215 = math.Round(conta.ValorDebitoFIP215*100.0) / 100.0
+			conta.SaldoAtualFIP215 = math.Round(conta.SaldoAtualFIP215*100.0) / 100.0
+			conta.ValorCreditoFIP215M = math.Round(conta.ValorCreditoFIP215M*100.0) / 100.0
+			conta.ValorDebitoFIP215M = math.Round(conta.ValorDebitoFIP215M*100.0) / 100.0
+			conta.SaldoAtualFIP215M = math.Round(conta.SaldoAtualFIP215M*100.0) / 100.0
+			conta.DiferencaCredito = math.Round((conta.ValorCreditoFIP215-conta.ValorCreditoFIP215M)*100.0) / 100.0
+			conta.DiferencaDebito = math.Round((conta.ValorDebitoFIP215-conta.ValorDebitoFIP215M)*100.0) / 100.0
+			conta.DiferencaSaldoAtual = math.Round((conta.SaldoAtualFIP215-conta.SaldoAtualFIP215M)*100.0) / 100.0
+
+			if conta.DiferencaCredito != 0 || conta.DiferencaDebito != 0 || conta.DiferencaSaldoAtual != 0 {
+				sort.Strings(conta.ContasContabeis)
+				resultado.Diferencas = append(resultado.Diferencas, *conta)
+			}
+		}
+
+		sort.Slice(resultado.Diferencas, func(i, j int) bool {
+			return resultado.Diferencas[i].CodigoContaSICONFI < resultado.Diferencas[j].CodigoContaSICONFI
+		})
+
+		resultado.Conciliado = len(resultado.Diferencas) == 0 && len(resultado.ContasSemMapeamento) == 0 && len(resultado.ContasSemMapeamentoUnico) == 0
+
+		conciliacao.PoderesOrgaos = append(conciliacao.PoderesOrgaos, resultado)
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	/*** Lógica Adicional ***/
+	conciliacao.Conciliado = len(conciliacao.PoderesOrgaosSemAssociacao) == 0
+
+	for _, resultado := range conciliacao.PoderesOrgaos {
+		conciliacao.Conciliado = conciliacao.Conciliado && resultado.Conciliado
+	}
+	/*** Lógica Adicional ***/
+
+	return c.JSON(http.StatusOK, conciliacao)
+}
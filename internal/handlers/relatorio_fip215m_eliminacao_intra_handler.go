@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type relatorioEliminacaoIntraFIP215M struct {
+	Dados          []dadoRelatorioFIP215M
+	Eliminacoes    []poderOrgaoRelatorioFIP215M
+	DiferencaIntra float64
+} // @name RelatorioEliminacaoIntraFIP215M
+
+// RelatorioFIP215MEliminacaoIntraHandler godoc
+//
+// @Summary     FIP215M - MSC Consolidada com Eliminação das Contas Intra-OFSS
+// @Description Emite a MSC consolidada do estado sem as contas intra-OFSS (classes 1 a 4 com 5º dígito igual a 2), que se anulam entre os poderes e órgãos, e lista os valores eliminados no consolidado (código 0) e em cada poder/órgão. Como a ACWTA8000 não registra a contrapartida, a eliminação não é detalhada por par de poderes/órgãos; a diferença intra é a soma dos saldos atuais eliminados, que é zero quando os registros intra-OFSS se correspondem
+// @Tags        Relatório
+// @Accept      json
+// @Produce     json
+// @Param       ano_exercicio  query    int true "Ano de Exercício"
+// @Param       mes_referencia query    int true "Mês de Referência"                                Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_contabil   query    int true "Mês Contábil (1-Execução / 2-Apuração / 3-Todos)" Enums(1, 2, 3)
+// @Success     200            {object} relatorioEliminacaoIntraFIP215M
+// @Failure     400            {object} Erro
+// @Failure     500            {object} Erro
+// @Router      /relatorio/fip_215m/eliminacao_intra [get]
+func RelatorioFIP215MEliminacaoIntraHandler(c echo.Context) error {
+	/*** Validação dos Parâmetros ***/
+	parametros, erros := lerParametrosPeriodoFIP215M(c)
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	msc, erro := consultarRelatorioFIP215MPoderesOrgaos(parametros)
+
+	if erro != nil {
+		return erro
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	/*** Lógica Adicional ***/
+	var eliminacao relatorioEliminacaoIntraFIP215M
+
+	// O primeiro poder/órgão é o consolidado do estado.
+	for i, poderOrgao := range msc.PoderesOrgaos {
+		eliminado := poderOrgaoRelatorioFIP215M{
+			CodigoPoderOrgao: poderOrgao.CodigoPoderOrgao,
+			NomePoderOrgao:   poderOrgao.NomePoderOrgao,
+		}
+
+		for _, dado := range poderOrgao.Dados {
+			if !contaContabilIntraOFSS(dado.CodigoContaSICONFI) {
+				if i == 0 {
+					eliminacao.Dados = append(eliminacao.Dados, dado)
+				}
+
+				continue
+			}
+
+			eliminado.Dados = append(eliminado.Dados, dado)
+
+			if i == 0 {
+				eliminacao.DiferencaIntra += dado.SaldoAtual
+			}
+		}
+
+		if i == 0 || len(eliminado.Dados) > 0 {
+			eliminacao.Eliminacoes = append(eliminacao.Eliminacoes, eliminado)
+		}
+	}
+
+	eliminacao.DiferencaIntra = math.Round(eliminacao.DiferencaIntra*100.0) / 100.0
+	/*** Lógica Adicional ***/
+
+	return c.JSON(http.StatusOK, eliminacao)
+}
@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -12,47 +18,225 @@ import (
 )
 
 type dadoRelatorioFIP215M struct {
-	CodigoContaSICONFI string  `json:"codigo_unidade_orcamentaria"`
-	ValorCredito       float64 `json:"valor_credito"`
-	ValorDebito        float64 `json:"valor_debito"`
-	SaldoAbertura      float64 `json:"saldo_atual"`
+	// Obsoleto: repete o código da conta SICONFI sob o nome original da
+	// chave, mantida até que os clientes passem a usar codigo_conta_siconfi.
+	CodigoUnidadeOrcamentaria string  `json:"codigo_unidade_orcamentaria"`
+	CodigoContaSICONFI        string  `json:"codigo_conta_siconfi"`
+	NaturezaSaldo             string  `json:"natureza_saldo"`
+	ValorCredito              float64 `json:"valor_credito"`
+	ValorDebito               float64 `json:"valor_debito"`
+	SaldoAtual                float64 `json:"saldo_atual"`
+	ValorSaldoAtual           float64 `json:"valor_saldo_atual"`
+	IndicadorSaldoAtual       string  `json:"indicador_saldo_atual"`
+	SaldoInvertido            bool    `json:"saldo_invertido"`
 } // @name DadoRelatorioFIP215M
 
+type informacaoComplementarFIP215M struct {
+	Tipo  string `json:"tipo"`
+	Valor string `json:"valor"`
+} // @name InformacaoComplementarFIP215M
+
+type linhaRelatorioFIP215M struct {
+	CodigoContaSICONFI        string                          `json:"codigo_conta_siconfi"`
+	InformacoesComplementares []informacaoComplementarFIP215M `json:"informacoes_complementares"`
+	Valor                     float64                         `json:"valor"`
+	TipoValor                 string                          `json:"tipo_valor"`
+	NaturezaValor             string                          `json:"natureza_valor"`
+} // @name LinhaRelatorioFIP215M
+
 type relatorioFIP215M struct {
-	Dados []dadoRelatorioFIP215M
+	Dados  []dadoRelatorioFIP215M
+	Linhas []linhaRelatorioFIP215M `json:"Linhas,omitempty"`
 } // @name RelatorioFIP215M
 
+type parametrosRelatorioFIP215M struct {
+	// FIPLAN
+	AnoExercicio              int
+	MesReferencia             int
+	MesContabil               int
+	CodigoPoderOrgao          int
+	InformacoesComplementares bool
+
+	// Adicionais
+	MesReferenciaNome         string
+	MesAnteriorReferenciaNome string
+	IndicesIC                 []int
+	PoderesOrgaos             bool
+}
+
+/*** Dados Estáticos ***/
+// Quantidade de informações complementares (IC1 a IC6) de cada linha da MSC.
+const quantidadeInformacoesComplementaresFIP215M = 6
+
+// Tipos de valor de uma linha da MSC, na ordem em que aparecem para cada
+// combinação de conta e informações complementares.
+const (
+	TipoValorSaldoInicialMSC = "beginning_balance"
+	TipoValorMovimentoMSC    = "period_change"
+	TipoValorSaldoFinalMSC   = "ending_balance"
+)
+
+/*** Dados Estáticos ***/
+
 // RelatorioFIP215MHandler godoc
 //
 // @Summary     FIP215M - Emitir Matriz de Saldos Contábeis - MSC SICONFI
 // @Description Emite a Matriz de Saldos Contábeis - MSC SICONFI
 // @Tags        Relatório
 // @Accept      json
-// @Produce     json
-// @Param       ano_exercicio                    query    int true "Ano de Exercício"
-// @Param       mes_referencia                   query    int true "Mês de Referência"                                Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
-// @Param       mes_contabil                     query    int true "Mês Contábil (1-Execução / 2-Apuração / 3-Todos)" Enums(1, 2, 3)
-// @Param       codigo_poder_orgao               query    int true "Código do Poder/Órgão SICONFI"
-// @Success     200                              {object} relatorioFIP215
-// @Failure     400                              {object} Erro
-// @Failure     500                              {object} Erro
-// @Router      /relatorio/fip_215 [get]
+// @Produce     json,text/csv,application/xml
+// @Param       ano_exercicio              query    int    true  "Ano de Exercício"
+// @Param       mes_referencia             query    int    true  "Mês de Referência"                                                                      Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_contabil               query    int    true  "Mês Contábil (1-Execução / 2-Apuração / 3-Todos)"                                       Enums(1, 2, 3)
+// @Param       codigo_poder_orgao         query    int    true  "Código do Poder/Órgão SICONFI"
+// @Param       informacoes_complementares query    bool   false "Detalhar as Linhas da MSC por Informações Complementares e Tipo de Valor?"              Enums(true, false)
+// @Param       formato                    query    string false "Formato de Saída (json / csv e xbrl no leiaute de envio ao SICONFI, sempre detalhados)" Enums(json, csv, xbrl)
+// @Success     200                        {object} relatorioFIP215M
+// @Failure     400                        {object} Erro
+// @Failure     500                        {object} Erro
+// @Router      /relatorio/fip_215m [get]
 func RelatorioFIP215MHandler(c echo.Context) error {
 	/*** Parâmetros ***/
 	parametros := struct {
-		// FIPLAN
-		AnoExercicio     int
-		MesReferencia    int
-		MesContabil      int
-		CodigoPoderOrgao int
-
-		// Adicionai
-		MesReferenciaNome string
-		NomePoderOrgao    string
+		parametrosRelatorioFIP215M
+
+		// Adicionais
+		Formato string
 	}{}
 	/*** Parâmetros ***/
 
 	/*** Validação dos Parâmetros ***/
+	var erros []string
+
+	parametros.parametrosRelatorioFIP215M, erros = lerParametrosRelatorioFIP215M(c)
+
+	parametros.Formato = "json"
+
+	if err := echo.QueryParamsBinder(c).String("formato", &parametros.Formato).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o formato de saída no parâmetro 'formato'.")
+	}
+
+	if err := Validate.Var(parametros.Formato, "oneof=json csv xbrl"); err != nil {
+		erros = append(erros, "Por favor, forneça um formato de saída válido (json, csv ou xbrl) para o parâmetro 'formato'.")
+	}
+
+	// Os arquivos de envio ao SICONFI são sempre compostos pelas linhas
+	// detalhadas da MSC.
+	if parametros.Formato != "json" {
+		parametros.InformacoesComplementares = true
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	erros, erro := poderOrgaoInexistenteFIP215M(parametros.parametrosRelatorioFIP215M)
+
+	if erro != nil {
+		return erro
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	identificadorEnte := os.Getenv("SICONFI_ID_ENTE")
+
+	if parametros.Formato != "json" && identificadorEnte == "" {
+		log.Printf("RelatorioFIP215MHandler: variável de ambiente SICONFI_ID_ENTE não configurada")
+		return ErroIdentificadorEnteSICONFI
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	msc, erro := consultarRelatorioFIP215M(parametros.parametrosRelatorioFIP215M)
+
+	if erro != nil {
+		return erro
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	/*** Lógica Adicional ***/
+	if parametros.Formato == "json" {
+		return c.JSON(http.StatusOK, msc)
+	}
+
+	arquivo := arquivoMSCFIP215M{
+		IdentificadorEnte: identificadorEnte,
+		AnoExercicio:      parametros.AnoExercicio,
+		MesReferencia:     parametros.MesReferencia,
+		Linhas:            msc.Linhas,
+	}
+
+	var conteudo []byte
+	var tipoConteudo string
+	var err error
+
+	if parametros.Formato == "csv" {
+		conteudo, err = arquivo.CSV()
+		tipoConteudo = "text/csv; charset=utf-8"
+	} else {
+		conteudo, err = arquivo.XBRL()
+		tipoConteudo = echo.MIMEApplicationXMLCharsetUTF8
+	}
+
+	if err != nil {
+		log.Printf("RelatorioFIP215MHandler: %v", err)
+		return ErroGeracaoArquivo
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", arquivo.NomeArquivo(parametros.Formato)))
+	/*** Lógica Adicional ***/
+
+	return c.Blob(http.StatusOK, tipoConteudo, conteudo)
+}
+
+// lerParametrosRelatorioFIP215M lê e valida os parâmetros da MSC, que também
+// são usados pelos relatórios derivados dela.
+func lerParametrosRelatorioFIP215M(c echo.Context) (parametrosRelatorioFIP215M, []string) {
+	parametros, erros := lerParametrosPeriodoFIP215M(c)
+
+	valueBinder := echo.QueryParamsBinder(c)
+
+	if err := valueBinder.MustInt("codigo_poder_orgao", &parametros.CodigoPoderOrgao).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o código do poder/órgão SICONFI no parâmetro 'codigo_poder_orgao'.")
+	}
+
+	if err := valueBinder.Bool("informacoes_complementares", &parametros.InformacoesComplementares).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça se as linhas da MSC devem ser detalhadas por informações complementares no parâmetro 'informacoes_complementares'.")
+	}
+
+	return parametros, erros
+}
+
+// poderOrgaoInexistenteFIP215M confere na tabela de poderes/órgãos SICONFI
+// (ACWTB0803) se o código informado existe no exercício, e retorna uma
+// mensagem quando não existe.
+func poderOrgaoInexistenteFIP215M(parametros parametrosRelatorioFIP215M) ([]string, *echo.HTTPError) {
+	if parametros.CodigoPoderOrgao == 0 {
+		return nil, nil
+	}
+
+	poderesOrgaos, erro := consultarPoderesOrgaosSICONFI(parametros.AnoExercicio)
+
+	if erro != nil {
+		return nil, erro
+	}
+
+	for _, poderOrgao := range poderesOrgaos {
+		if poderOrgao.Codigo == parametros.CodigoPoderOrgao {
+			return nil, nil
+		}
+	}
+
+	return []string{fmt.Sprintf("Não existe poder/órgão SICONFI com o código %d (parâmetro 'codigo_poder_orgao') no exercício %d.", parametros.CodigoPoderOrgao, parametros.AnoExercicio)}, nil
+}
+
+// lerParametrosPeriodoFIP215M lê e valida o exercício, o mês de referência e o
+// mês contábil da MSC.
+func lerParametrosPeriodoFIP215M(c echo.Context) (parametrosRelatorioFIP215M, []string) {
+	var parametros parametrosRelatorioFIP215M
+
 	valueBinder := echo.QueryParamsBinder(c)
 
 	var erros []string
@@ -73,6 +257,7 @@ func RelatorioFIP215MHandler(c echo.Context) error {
 		erros = append(erros, "Por favor, forneça um mês de referência válido entre 1 e 12 para o parâmetro 'mes_referencia'.")
 	} else {
 		parametros.MesReferenciaNome = MesParaNome[parametros.MesReferencia]
+		parametros.MesAnteriorReferenciaNome = MesParaNome[parametros.MesReferencia-1]
 	}
 
 	if err := valueBinder.MustInt("mes_contabil", &parametros.MesContabil).BindError(); err != nil {
@@ -83,148 +268,486 @@ func RelatorioFIP215MHandler(c echo.Context) error {
 		erros = append(erros, "Por favor, forneça um mês contábil válido entre 1 e 3 para o parâmetro 'mes_contabil'.")
 	}
 
-	if err := valueBinder.MustInt("codigo_poder_orgao", &parametros.CodigoPoderOrgao).BindError(); err != nil {
-		erros = append(erros, "Por favor, forneça o código do poder/órgão SICONFI no parâmetro 'codigo_poder_orgao'.")
-	}
-
 	if parametros.MesReferencia != 12 && parametros.MesContabil != 1 {
 		erros = append(erros, "A opção de emissão do relatório para o mês contábil 2 (apuração) ou 3 (todos) só está disponível para o mês de referência 12 (dezembro).")
 	}
 
-	if len(erros) > 0 {
-		return ErroValidacaoParametro(erros)
-	}
-	/*** Validação dos Parâmetros ***/
+	return parametros, erros
+}
 
-	/*** Consulta no Banco de Dados ***/
+// consultarRelatorioFIP215M consulta a MSC por conta SICONFI e, quando pedido,
+// as suas linhas detalhadas por informações complementares e tipo de valor.
+func consultarRelatorioFIP215M(parametros parametrosRelatorioFIP215M) (relatorioFIP215M, *echo.HTTPError) {
 	var sqlQuery strings.Builder
 
 	var msc relatorioFIP215M
 
-	if parametros.CodigoPoderOrgao != 0 {
-		queryTemplate := `SELECT UNIQUE NOME_PODER_ORGAO_SICONFI
-		
-																FROM ACWTB0803
+	queryTemplate := queryDadosRelatorioFIP215M
+
+	tmplContaExplosao, err := template.New("queryMSC").Funcs(FuncoesTemplate).Parse(queryTemplate)
+
+	if err != nil {
+		log.Printf("RelatorioFIP215MHandler: %v", err)
+		return msc, ErroMontagemTemplate
+	}
+
+	err = tmplContaExplosao.Execute(&sqlQuery, parametros)
+
+	if err != nil {
+		log.Printf("RelatorioFIP215MHandler: %v", err)
+		return msc, ErroExecucaoTemplate
+	}
+
+	compactSqlQuery := strings.Join(strings.Fields(sqlQuery.String()), " ")
+	log.Printf("RelatorioFIP215MHandler: %s", compactSqlQuery)
+	rows, err := Db.Query(compactSqlQuery)
+
+	sqlQuery.Reset()
+
+	if err != nil {
+		log.Printf("RelatorioFIP215MHandler: %v", err)
+		return msc, ErroConsultaBancoDados
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var dado dadoRelatorioFIP215M
+		var naturezaSaldo int
+
+		if err := rows.Scan(
+			&dado.CodigoContaSICONFI,
+			&naturezaSaldo,
+			&dado.ValorCredito,
+			&dado.ValorDebito,
+			&dado.SaldoAtual,
+		); err != nil {
+			log.Printf("RelatorioFIP215MHandler: %v", err)
+			return msc, ErroConsultaLinhaBancoDados
+		}
+
+		dado.CodigoUnidadeOrcamentaria = dado.CodigoContaSICONFI
+		dado.NaturezaSaldo = NaturezaSaldoParaNome[naturezaSaldo]
+		dado.ValorSaldoAtual, dado.IndicadorSaldoAtual = IndicadorSaldo(dado.SaldoAtual)
+		dado.SaldoInvertido = SaldoInvertido(dado.NaturezaSaldo, dado.IndicadorSaldoAtual)
+
+		msc.Dados = append(msc.Dados, dado)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("RelatorioFIP215MHandler: %v", err)
+		return msc, ErroRedeOuResultadoBancoDados
+	}
 
-																WHERE CODG_PODER_ORGAO_SICONFI = {{.codigo_poder_orgao}}
-																AND CD_EXERCICIO = {{.AnoExercicio}}`
+	if parametros.InformacoesComplementares {
+		for i := 1; i <= quantidadeInformacoesComplementaresFIP215M; i++ {
+			parametros.IndicesIC = append(parametros.IndicesIC, i)
+		}
+
+		// Cada combinação de conta e informações complementares gera as linhas
+		// de saldo inicial, de movimento a débito e a crédito e de saldo final.
+		// Assim como na consulta por conta, só entram as contas SICONFI do
+		// mapeamento do exercício.
+		queryTemplate := `SELECT SA.CODG_CONTA_SICONFI,
+
+		                  {{range .IndicesIC}}
+		                  NVL(TO_CHAR(SA.TIPO_IC{{.}}), ' ') TIPO_IC{{.}},
+		                  NVL(TO_CHAR(SA.IC{{.}}), ' ') IC{{.}},
+		                  {{end}}
+
+		                  {{if eq .MesReferencia 1}}
+		                  NVL(SUM(SA.SALDO_ABERTURA),0) SALDO_INICIAL,
+		                  {{else}}
+		                  NVL(SUM(SA.SALDO_ABERTURA),0) + NVL(SUM(SA.VALR_{{.MesAnteriorReferenciaNome}}),0) SALDO_INICIAL,
+		                  {{end}}
+
+		                  NVL(SUM(SA.VALR_DEB_{{.MesReferenciaNome}}),0) DEBITO,
+		                  NVL(SUM(SA.VALR_CRE_{{.MesReferenciaNome}}),0) CREDITO,
+		                  NVL(SUM(SA.SALDO_ABERTURA),0) + NVL(SUM(SA.VALR_{{.MesReferenciaNome}}),0) SALDO_FINAL
+
+		                  FROM
+		                  (
+		                  	SELECT DISTINCT MAPA.CD_EXERCICIO, MAPA.CODG_CONTA_SICONFI
+		                  	FROM ACWTB0801 MAPA
+		                  ) CCS,
+		                  ACWTA8000 SA
+
+		                  WHERE SA.CODG_CONTA_SICONFI=CCS.CODG_CONTA_SICONFI
+		                  AND SA.CD_EXERCICIO=CCS.CD_EXERCICIO
+		                  AND SA.CD_EXERCICIO={{.AnoExercicio}}
 
-		tmpl, err := template.New("queryMSC").Parse(queryTemplate)
+		                  {{if eq .MesContabil 1}}
+		                  AND SA.FLAG_MES_CONTABIL={{dominio "MES_CONTABIL_EXECUCAO"}}
+		                  {{else if eq .MesContabil 2}}
+		                  AND SA.FLAG_MES_CONTABIL={{dominio "MES_CONTABIL_APURACAO"}}
+		                  {{end}}
+
+		                  {{if .CodigoPoderOrgao}}
+		                  AND SA.IC1 LIKE '{{.CodigoPoderOrgao}} - %'
+		                  {{end}}
+
+		                  GROUP BY SA.CODG_CONTA_SICONFI
+		                  {{range .IndicesIC}}, SA.TIPO_IC{{.}}, SA.IC{{.}}{{end}}
+
+		                  ORDER BY SA.CODG_CONTA_SICONFI
+		                  {{range .IndicesIC}}, TIPO_IC{{.}}, IC{{.}}{{end}}`
+
+		tmplLinhas, err := template.New("queryLinhasMSC").Funcs(FuncoesTemplate).Parse(queryTemplate)
 
 		if err != nil {
 			log.Printf("RelatorioFIP215MHandler: %v", err)
-			return ErroMontagemTemplate
+			return msc, ErroMontagemTemplate
 		}
 
-		err = tmpl.Execute(&sqlQuery, parametros)
+		err = tmplLinhas.Execute(&sqlQuery, parametros)
 
 		if err != nil {
 			log.Printf("RelatorioFIP215MHandler: %v", err)
-			return ErroExecucaoTemplate
+			return msc, ErroExecucaoTemplate
 		}
 
 		compactSqlQuery := strings.Join(strings.Fields(sqlQuery.String()), " ")
 		log.Printf("RelatorioFIP215MHandler: %s", compactSqlQuery)
-		row := Db.QueryRow(compactSqlQuery)
+		rowsLinhas, err := Db.Query(compactSqlQuery)
 
-		var nomePoderOrgao string
+		sqlQuery.Reset()
 
-		if err := row.Scan(
-			&nomePoderOrgao,
-		); err != nil {
-			log.Printf("RelatorioFIP215Handler: %v", err)
-			return ErroConsultaLinhaBancoDados
+		if err != nil {
+			log.Printf("RelatorioFIP215MHandler: %v", err)
+			return msc, ErroConsultaBancoDados
 		}
 
-		parametros.NomePoderOrgao = fmt.Sprintf("%d - %s", parametros.CodigoPoderOrgao, nomePoderOrgao)
+		defer rowsLinhas.Close()
+
+		for rowsLinhas.Next() {
+			var codigoContaSICONFI string
+			var tiposIC, valoresIC [quantidadeInformacoesComplementaresFIP215M]string
+			var saldoInicial, debito, credito, saldoFinal float64
+
+			destinos := []any{&codigoContaSICONFI}
+
+			for i := range tiposIC {
+				destinos = append(destinos, &tiposIC[i], &valoresIC[i])
+			}
+
+			destinos = append(destinos, &saldoInicial, &debito, &credito, &saldoFinal)
+
+			if err := rowsLinhas.Scan(destinos...); err != nil {
+				log.Printf("RelatorioFIP215MHandler: %v", err)
+				return msc, ErroConsultaLinhaBancoDados
+			}
+
+			var informacoesComplementares []informacaoComplementarFIP215M
+
+			for i := range tiposIC {
+				if tiposIC[i] == " " && valoresIC[i] == " " {
+					continue
+				}
+
+				informacoesComplementares = append(informacoesComplementares, informacaoComplementarFIP215M{
+					Tipo:  strings.TrimSpace(tiposIC[i]),
+					Valor: strings.TrimSpace(valoresIC[i]),
+				})
+			}
+
+			// Os saldos seguem o sinal do FIP215M (positivo é credor) e os
+			// movimentos já vêm separados em débito e crédito.
+			valores := []struct {
+				TipoValor     string
+				Valor         float64
+				NaturezaValor string
+			}{
+				{TipoValor: TipoValorSaldoInicialMSC, Valor: saldoInicial},
+				{TipoValor: TipoValorMovimentoMSC, Valor: debito, NaturezaValor: "D"},
+				{TipoValor: TipoValorMovimentoMSC, Valor: credito, NaturezaValor: "C"},
+				{TipoValor: TipoValorSaldoFinalMSC, Valor: saldoFinal},
+			}
+
+			for _, valor := range valores {
+				if valor.NaturezaValor == "" {
+					valor.Valor, valor.NaturezaValor = IndicadorSaldo(valor.Valor)
+				}
+
+				valor.Valor = math.Round(valor.Valor*100.0) / 100.0
+
+				// A MSC não traz linhas de valor zero.
+				if valor.Valor == 0 {
+					continue
+				}
+
+				msc.Linhas = append(msc.Linhas, linhaRelatorioFIP215M{
+					CodigoContaSICONFI:        codigoContaSICONFI,
+					InformacoesComplementares: informacoesComplementares,
+					Valor:                     valor.Valor,
+					TipoValor:                 valor.TipoValor,
+					NaturezaValor:             valor.NaturezaValor,
+				})
+			}
+		}
 
-		sqlQuery.Reset()
-	} else {
-		parametros.NomePoderOrgao = "CONSOLIDADO DO ESTADO"
+		if err := rowsLinhas.Err(); err != nil {
+			log.Printf("RelatorioFIP215MHandler: %v", err)
+			return msc, ErroRedeOuResultadoBancoDados
+		}
 	}
 
-	queryTemplate := `SELECT CCS.CODG_CONTA_SICONFI,
-	                  NVL(SUM(SA.VALR_CRE_{{.MesReferenciaNome}}),0) CREDITO,
-										NVL(SUM(SA.VALR_DEB_{{.MesReferenciaNome}}),0) DEBITO,
+	return msc, nil
+}
 
-										{{if eq .MesReferencia 1}}
-			              NVL(SUM(SA.SALDO_ABERTURA),0) SALDO_ABERTURA
-										{{else}}
-			              NVL(SUM(SA.SALDO_ABERTURA),0) + NVL(SUM(SA.VALR_{{.MesReferenciaNome}}),0) SALDO_ABERTURA
-										{{end}}
-		 
-		                FROM
-										ACWTB0801 CCS,
-										ACWTA8000 SA
+// O saldo atual é o de abertura somado ao acumulado até o fim do mês de
+// referência (VALR_<mês>), inclusive em janeiro, como o saldo final das linhas
+// detalhadas. A natureza da conta SICONFI é a das contas contábeis mapeadas
+// nela, ou mista quando elas não têm a mesma natureza. Com PoderesOrgaos, os
+// valores são agrupados também por poder/órgão (IC1), e as linhas com
+// GROUPING(SA.IC1) igual a 1 trazem o consolidado do estado. As contas SICONFI
+// vêm distintas do mapeamento, porque uma conta SICONFI mapeada em várias
+// contas contábeis somaria o seu saldo uma vez para cada mapeamento. O
+// poder/órgão é filtrado pelo código no início do IC1 ("10131 - NOME").
+const queryDadosRelatorioFIP215M = `SELECT
+	{{if .PoderesOrgaos}}
+	NVL(TO_CHAR(SA.IC1), ' ') PODER_ORGAO,
+	GROUPING(SA.IC1) CONSOLIDADO,
+	{{end}}
+	CCS.CODG_CONTA_SICONFI,
+	NVL(NATUREZA.NATUREZA_SALDO, 0) NATUREZA_SALDO,
+	NVL(SUM(SA.VALR_CRE_{{.MesReferenciaNome}}),0) CREDITO,
+	NVL(SUM(SA.VALR_DEB_{{.MesReferenciaNome}}),0) DEBITO,
+	NVL(SUM(SA.SALDO_ABERTURA),0) + NVL(SUM(SA.VALR_{{.MesReferenciaNome}}),0) SALDO_ABERTURA
+
+	FROM
+	(
+		SELECT DISTINCT MAPA.CD_EXERCICIO, MAPA.CODG_CONTA_SICONFI
+		FROM ACWTB0801 MAPA
+	) CCS,
+	ACWTA8000 SA,
+	(
+		SELECT MAPA.CD_EXERCICIO,
+		MAPA.CODG_CONTA_SICONFI,
+		CASE WHEN MIN(DOMINIO_NATUREZA.CD_ITEM_DOMINIO) = MAX(DOMINIO_NATUREZA.CD_ITEM_DOMINIO)
+		THEN MIN(DOMINIO_NATUREZA.CD_ITEM_DOMINIO)
+		ELSE 3 END NATUREZA_SALDO
+
+		FROM ACWTB0801 MAPA
+		INNER JOIN ACWTB0032 CC ON (CC.CODG_CONTA_CONTABIL = MAPA.CODG_CONTA_CONTABIL AND CC.CD_EXERCICIO = MAPA.CD_EXERCICIO)
+		INNER JOIN ITEM_DOMINIO DOMINIO_NATUREZA ON (CC.FLAG_NATUREZA_SALDO = DOMINIO_NATUREZA.ID_ITEM_DOMINIO)
+
+		WHERE MAPA.CD_EXERCICIO={{.AnoExercicio}}
+
+		GROUP BY MAPA.CD_EXERCICIO, MAPA.CODG_CONTA_SICONFI
+	) NATUREZA
+
+	WHERE SA.CODG_CONTA_SICONFI=CCS.CODG_CONTA_SICONFI
+	AND NATUREZA.CODG_CONTA_SICONFI(+)=CCS.CODG_CONTA_SICONFI
+	AND NATUREZA.CD_EXERCICIO(+)=CCS.CD_EXERCICIO
+	AND SA.CD_EXERCICIO=CCS.CD_EXERCICIO
+	AND SA.CD_EXERCICIO={{.AnoExercicio}}
+
+	{{if eq .MesContabil 1}}
+	AND SA.FLAG_MES_CONTABIL={{dominio "MES_CONTABIL_EXECUCAO"}}
+	{{else if eq .MesContabil 2}}
+	AND SA.FLAG_MES_CONTABIL={{dominio "MES_CONTABIL_APURACAO"}}
+	{{end}}
+
+	{{if .CodigoPoderOrgao}}
+	AND SA.IC1 LIKE '{{.CodigoPoderOrgao}} - %'
+	{{end}}
+
+	{{if .PoderesOrgaos}}
+	GROUP BY GROUPING SETS (
+		(SA.IC1, CCS.CODG_CONTA_SICONFI, NATUREZA.NATUREZA_SALDO),
+		(CCS.CODG_CONTA_SICONFI, NATUREZA.NATUREZA_SALDO)
+	)
+
+	ORDER BY CONSOLIDADO DESC, PODER_ORGAO, CCS.CODG_CONTA_SICONFI
+	{{else}}
+	GROUP BY CCS.CODG_CONTA_SICONFI, NATUREZA.NATUREZA_SALDO
+
+	ORDER BY CCS.CODG_CONTA_SICONFI
+	{{end}}`
+
+/*** Arquivos SICONFI ***/
+var ErroIdentificadorEnteSICONFI *echo.HTTPError = echo.NewHTTPError(http.StatusInternalServerError, "O identificador do ente no SICONFI não está configurado (variável de ambiente SICONFI_ID_ENTE).")
+
+// arquivoMSCFIP215M monta os arquivos da MSC no leiaute de envio ao SICONFI a
+// partir das linhas detalhadas por informações complementares.
+type arquivoMSCFIP215M struct {
+	IdentificadorEnte string
+	AnoExercicio      int
+	MesReferencia     int
+	Linhas            []linhaRelatorioFIP215M
+}
 
-                    WHERE SA.CODG_CONTA_SICONFI=CCS.CODG_CONTA_SICONFI
-                    AND SA.CD_EXERCICIO=CCS.CD_EXERCICIO
-                    AND SA.CD_EXERCICIO={{.AnoExercicio}}
+// Periodo retorna o período da MSC no formato AAAA-MM.
+func (a arquivoMSCFIP215M) Periodo() string {
+	return fmt.Sprintf("%04d-%02d", a.AnoExercicio, a.MesReferencia)
+}
 
-										{{if eq .MesContabil 1}}
-                    AND SA.FLAG_MES_CONTABIL=1428
-										{{else if eq .MesContabil 2}}
-                    AND SA.FLAG_MES_CONTABIL=1429
-										{{end}}
+// NomeArquivo retorna o nome do arquivo, como em MSC_14_2023-04.csv.
+func (a arquivoMSCFIP215M) NomeArquivo(formato string) string {
+	extensao := formato
 
-										{{if .CodigoPoderOrgao}}
-                    AND SA.IC1='{{.NomePoderOrgao}}'
-										{{end}}
+	if formato == "xbrl" {
+		extensao = "xml"
+	}
 
-                    GROUP BY CCS.CODG_CONTA_SICONFI`
+	return fmt.Sprintf("MSC_%s_%s.%s", a.IdentificadorEnte, a.Periodo(), extensao)
+}
 
-	tmplContaExplosao, err := template.New("queryMSC").Parse(queryTemplate)
+// CSV gera o arquivo separado por ponto e vírgula, com o ente e o período na
+// primeira linha, o cabeçalho na segunda e uma linha da MSC por linha do arquivo.
+func (a arquivoMSCFIP215M) CSV() ([]byte, error) {
+	var conteudo bytes.Buffer
 
-	if err != nil {
-		log.Printf("RelatorioFIP215MHandler: %v", err)
-		return ErroMontagemTemplate
+	escritor := csv.NewWriter(&conteudo)
+	escritor.Comma = ';'
+
+	cabecalho := []string{"CONTA"}
+
+	for i := 1; i <= quantidadeInformacoesComplementaresFIP215M; i++ {
+		cabecalho = append(cabecalho, fmt.Sprintf("IC%d", i), fmt.Sprintf("TIPO%d", i))
 	}
 
-	err = tmplContaExplosao.Execute(&sqlQuery, parametros.AnoExercicio)
+	cabecalho = append(cabecalho, "VALOR", "TIPO_VALOR", "NATUREZA_VALOR")
 
-	if err != nil {
-		log.Printf("RelatorioFIP215MHandler: %v", err)
-		return ErroExecucaoTemplate
+	registros := [][]string{{a.IdentificadorEnte, a.Periodo()}, cabecalho}
+
+	for _, linha := range a.Linhas {
+		registro := []string{linha.CodigoContaSICONFI}
+
+		for i := 0; i < quantidadeInformacoesComplementaresFIP215M; i++ {
+			if i < len(linha.InformacoesComplementares) {
+				registro = append(registro, linha.InformacoesComplementares[i].Valor, linha.InformacoesComplementares[i].Tipo)
+			} else {
+				registro = append(registro, "", "")
+			}
+		}
+
+		registro = append(registro, strconv.FormatFloat(linha.Valor, 'f', 2, 64), linha.TipoValor, linha.NaturezaValor)
+		registros = append(registros, registro)
 	}
 
-	compactSqlQuery := strings.Join(strings.Fields(sqlQuery.String()), " ")
-	log.Printf("RelatorioFIP215MHandler: %s", compactSqlQuery)
-	rows, err := Db.Query(compactSqlQuery)
+	if err := escritor.WriteAll(registros); err != nil {
+		return nil, err
+	}
 
-	sqlQuery.Reset()
+	return conteudo.Bytes(), nil
+}
 
-	if err != nil {
-		log.Printf("RelatorioFIP215MHandler: %v", err)
-		return ErroConsultaBancoDados
+type xbrlMSC struct {
+	XMLName       xml.Name           `xml:"xbrli:xbrl"`
+	NamespaceXBRL string             `xml:"xmlns:xbrli,attr"`
+	NamespaceGL   string             `xml:"xmlns:gl-cor,attr"`
+	NamespaceISO  string             `xml:"xmlns:iso4217,attr"`
+	NamespaceLink string             `xml:"xmlns:link,attr"`
+	NamespaceHref string             `xml:"xmlns:xlink,attr"`
+	SchemaRef     xbrlSchemaRefMSC   `xml:"link:schemaRef"`
+	Contexto      xbrlContextoMSC    `xml:"xbrli:context"`
+	Unidade       xbrlUnidadeMSC     `xml:"xbrli:unit"`
+	Lancamentos   xbrlLancamentosMSC `xml:"gl-cor:accountingEntries"`
+}
+
+type xbrlSchemaRefMSC struct {
+	Tipo string `xml:"xlink:type,attr"`
+	Href string `xml:"xlink:href,attr"`
+}
+
+type xbrlContextoMSC struct {
+	ID            string `xml:"id,attr"`
+	Identificador struct {
+		Esquema string `xml:"scheme,attr"`
+		Valor   string `xml:",chardata"`
+	} `xml:"xbrli:entity>xbrli:identifier"`
+	Instante string `xml:"xbrli:period>xbrli:instant"`
+}
+
+type xbrlUnidadeMSC struct {
+	ID     string `xml:"id,attr"`
+	Medida string `xml:"xbrli:measure"`
+}
+
+type xbrlValorMSC struct {
+	Contexto string `xml:"contextRef,attr"`
+	Valor    string `xml:",chardata"`
+}
+
+type xbrlLancamentosMSC struct {
+	TipoLancamentos xbrlValorMSC     `xml:"gl-cor:documentInfo>gl-cor:entriesType"`
+	Detalhes        []xbrlDetalheMSC `xml:"gl-cor:entryHeader>gl-cor:entryDetail"`
+}
+
+type xbrlSubContaMSC struct {
+	ID   xbrlValorMSC `xml:"gl-cor:accountSubID"`
+	Tipo xbrlValorMSC `xml:"gl-cor:accountSubType"`
+}
+
+type xbrlDetalheMSC struct {
+	Conta     xbrlValorMSC      `xml:"gl-cor:account>gl-cor:accountMainID"`
+	SubContas []xbrlSubContaMSC `xml:"gl-cor:account>gl-cor:accountSub"`
+	Valor     struct {
+		Contexto string `xml:"contextRef,attr"`
+		Unidade  string `xml:"unitRef,attr"`
+		Decimais string `xml:"decimals,attr"`
+		Valor    string `xml:",chardata"`
+	} `xml:"gl-cor:amount"`
+	Natureza  xbrlValorMSC `xml:"gl-cor:debitCreditCode"`
+	TipoValor xbrlValorMSC `xml:"gl-cor:xbrlInfo>gl-cor:xbrlInclude"`
+}
+
+// XBRL gera o documento de instância XBRL GL da MSC, com um contexto para o
+// ente no último dia do mês de referência e um lançamento por linha da MSC.
+func (a arquivoMSCFIP215M) XBRL() ([]byte, error) {
+	const contexto = "C1"
+
+	documento := xbrlMSC{
+		NamespaceXBRL: "http://www.xbrl.org/2003/instance",
+		NamespaceGL:   "http://www.xbrl.org/int/gl/cor/2015-03-25",
+		NamespaceISO:  "http://www.xbrl.org/2003/iso4217",
+		NamespaceLink: "http://www.xbrl.org/2003/linkbase",
+		NamespaceHref: "http://www.w3.org/1999/xlink",
+		SchemaRef: xbrlSchemaRefMSC{
+			Tipo: "simple",
+			Href: "../plt/case-c-b-m-u-t-s/gl-plt-all-2015-03-25.xsd",
+		},
+		Contexto: xbrlContextoMSC{
+			ID:       contexto,
+			Instante: time.Date(a.AnoExercicio, time.Month(a.MesReferencia+1), 0, 0, 0, 0, 0, time.UTC).Format(time.DateOnly),
+		},
+		Unidade: xbrlUnidadeMSC{ID: "BRL", Medida: "iso4217:BRL"},
 	}
 
-	defer rows.Close()
+	documento.Contexto.Identificador.Esquema = "http://siconfi.tesouro.gov.br"
+	documento.Contexto.Identificador.Valor = a.IdentificadorEnte
+	documento.Lancamentos.TipoLancamentos = xbrlValorMSC{Contexto: contexto, Valor: "balance"}
 
-	for rows.Next() {
-		var dado dadoRelatorioFIP215M
+	for _, linha := range a.Linhas {
+		detalhe := xbrlDetalheMSC{
+			Conta:     xbrlValorMSC{Contexto: contexto, Valor: linha.CodigoContaSICONFI},
+			Natureza:  xbrlValorMSC{Contexto: contexto, Valor: linha.NaturezaValor},
+			TipoValor: xbrlValorMSC{Contexto: contexto, Valor: linha.TipoValor},
+		}
 
-		if err := rows.Scan(
-			&dado.CodigoContaSICONFI,
-			&dado.ValorCredito,
-			&dado.ValorDebito,
-			&dado.SaldoAbertura,
-		); err != nil {
-			log.Printf("RelatorioFIP215Handler: %v", err)
-			return ErroConsultaLinhaBancoDados
+		for _, informacaoComplementar := range linha.InformacoesComplementares {
+			detalhe.SubContas = append(detalhe.SubContas, xbrlSubContaMSC{
+				ID:   xbrlValorMSC{Contexto: contexto, Valor: informacaoComplementar.Valor},
+				Tipo: xbrlValorMSC{Contexto: contexto, Valor: informacaoComplementar.Tipo},
+			})
 		}
 
-		msc.Dados = append(msc.Dados, dado)
-	}
+		detalhe.Valor.Contexto = contexto
+		detalhe.Valor.Unidade = "BRL"
+		detalhe.Valor.Decimais = "2"
+		detalhe.Valor.Valor = strconv.FormatFloat(linha.Valor, 'f', 2, 64)
 
-	if err := rows.Err(); err != nil {
-		log.Printf("RelatorioFIP215Handler: %v", err)
-		return ErroRedeOuResultadoBancoDados
+		documento.Lancamentos.Detalhes = append(documento.Lancamentos.Detalhes, detalhe)
 	}
 
-	/*** Consulta no Banco de Dados ***/
+	conteudo, err := xml.MarshalIndent(documento, "", "  ")
 
-	/*** Lógica Adicional ***/
-	/*** Lógica Adicional ***/
+	if err != nil {
+		return nil, err
+	}
 
-	return c.JSON(http.StatusOK, msc)
+	return append([]byte(xml.Header), conteudo...), nil
 }
+
+/*** Arquivos SICONFI ***/
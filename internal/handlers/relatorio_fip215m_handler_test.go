@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func arquivoMSCTesteFIP215M() arquivoMSCFIP215M {
+	return arquivoMSCFIP215M{
+		IdentificadorEnte: "14",
+		AnoExercicio:      2024,
+		MesReferencia:     2,
+		Linhas: []linhaRelatorioFIP215M{
+			{
+				CodigoContaSICONFI: "111110100",
+				InformacoesComplementares: []informacaoComplementarFIP215M{
+					{Tipo: "PO", Valor: "10131"},
+					{Tipo: "FP", Valor: "1"},
+				},
+				Valor:         1234.5,
+				TipoValor:     TipoValorSaldoFinalMSC,
+				NaturezaValor: "D",
+			},
+			{
+				CodigoContaSICONFI: "211110101",
+				Valor:              10,
+				TipoValor:          TipoValorMovimentoMSC,
+				NaturezaValor:      "C",
+			},
+		},
+	}
+}
+
+func TestNomeArquivoMSCFIP215M(t *testing.T) {
+	casos := []struct {
+		formato string
+		nome    string
+	}{
+		{"csv", "MSC_14_2024-02.csv"},
+		{"xbrl", "MSC_14_2024-02.xml"},
+	}
+
+	for _, caso := range casos {
+		if nome := arquivoMSCTesteFIP215M().NomeArquivo(caso.formato); nome != caso.nome {
+			t.Errorf("NomeArquivo(%q) = %q, esperado %q", caso.formato, nome, caso.nome)
+		}
+	}
+}
+
+func TestCSVArquivoMSCFIP215M(t *testing.T) {
+	conteudo, err := arquivoMSCTesteFIP215M().CSV()
+
+	if err != nil {
+		t.Fatalf("CSV() retornou erro: %v", err)
+	}
+
+	esperado := strings.Join([]string{
+		"14;2024-02",
+		"CONTA;IC1;TIPO1;IC2;TIPO2;IC3;TIPO3;IC4;TIPO4;IC5;TIPO5;IC6;TIPO6;VALOR;TIPO_VALOR;NATUREZA_VALOR",
+		"111110100;10131;PO;1;FP;;;;;;;;;1234.50;ending_balance;D",
+		"211110101;;;;;;;;;;;;;10.00;period_change;C",
+	}, "\n") + "\n"
+
+	if string(conteudo) != esperado {
+		t.Errorf("CSV() = %q, esperado %q", conteudo, esperado)
+	}
+}
+
+func TestXBRLArquivoMSCFIP215M(t *testing.T) {
+	conteudo, err := arquivoMSCTesteFIP215M().XBRL()
+
+	if err != nil {
+		t.Fatalf("XBRL() retornou erro: %v", err)
+	}
+
+	documento := string(conteudo)
+
+	casos := []struct {
+		nome   string
+		trecho string
+	}{
+		{"cabeçalho XML", `<?xml version="1.0" encoding="UTF-8"?>`},
+		{"ente", `<xbrli:identifier scheme="http://siconfi.tesouro.gov.br">14</xbrli:identifier>`},
+		{"último dia do mês de referência", `<xbrli:instant>2024-02-29</xbrli:instant>`},
+		{"tipo dos lançamentos", `<gl-cor:entriesType contextRef="C1">balance</gl-cor:entriesType>`},
+		{"conta", `<gl-cor:accountMainID contextRef="C1">111110100</gl-cor:accountMainID>`},
+		{"informação complementar", `<gl-cor:accountSubID contextRef="C1">10131</gl-cor:accountSubID>`},
+		{"tipo da informação complementar", `<gl-cor:accountSubType contextRef="C1">PO</gl-cor:accountSubType>`},
+		{"valor", `<gl-cor:amount contextRef="C1" unitRef="BRL" decimals="2">1234.50</gl-cor:amount>`},
+		{"natureza do valor", `<gl-cor:debitCreditCode contextRef="C1">C</gl-cor:debitCreditCode>`},
+		{"tipo do valor", `<gl-cor:xbrlInclude contextRef="C1">ending_balance</gl-cor:xbrlInclude>`},
+	}
+
+	for _, caso := range casos {
+		if !strings.Contains(documento, caso.trecho) {
+			t.Errorf("XBRL() sem %s: %q não encontrado", caso.nome, caso.trecho)
+		}
+	}
+
+	if quantidade := strings.Count(documento, "<gl-cor:entryDetail>"); quantidade != 2 {
+		t.Errorf("XBRL() com %d lançamentos, esperado 2", quantidade)
+	}
+
+	if quantidade := strings.Count(documento, "<gl-cor:accountSub>"); quantidade != 2 {
+		t.Errorf("XBRL() com %d informações complementares, esperado 2", quantidade)
+	}
+}
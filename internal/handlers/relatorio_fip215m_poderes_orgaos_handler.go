@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/labstack/echo/v4"
+)
+
+type poderOrgaoRelatorioFIP215M struct {
+	CodigoPoderOrgao int                    `json:"codigo_poder_orgao"`
+	NomePoderOrgao   string                 `json:"nome_poder_orgao"`
+	Dados            []dadoRelatorioFIP215M `json:"dados"`
+} // @name PoderOrgaoRelatorioFIP215M
+
+type relatorioPoderesOrgaosFIP215M struct {
+	PoderesOrgaos []poderOrgaoRelatorioFIP215M
+} // @name RelatorioPoderesOrgaosFIP215M
+
+// RelatorioFIP215MPoderesOrgaosHandler godoc
+//
+// @Summary     FIP215M - MSC de Todos os Poderes/Órgãos e do Consolidado
+// @Description Emite, em uma única consulta agrupada, a MSC por conta SICONFI do consolidado do estado (código 0) e de cada poder/órgão SICONFI do exercício
+// @Tags        Relatório
+// @Accept      json
+// @Produce     json
+// @Param       ano_exercicio  query    int true "Ano de Exercício"
+// @Param       mes_referencia query    int true "Mês de Referência"                                Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_contabil   query    int true "Mês Contábil (1-Execução / 2-Apuração / 3-Todos)" Enums(1, 2, 3)
+// @Success     200            {object} relatorioPoderesOrgaosFIP215M
+// @Failure     400            {object} Erro
+// @Failure     500            {object} Erro
+// @Router      /relatorio/fip_215m/poderes_orgaos [get]
+func RelatorioFIP215MPoderesOrgaosHandler(c echo.Context) error {
+	/*** Validação dos Parâmetros ***/
+	parametros, erros := lerParametrosPeriodoFIP215M(c)
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	relatorio, erro := consultarRelatorioFIP215MPoderesOrgaos(parametros)
+
+	if erro != nil {
+		return erro
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	return c.JSON(http.StatusOK, relatorio)
+}
+
+// consultarRelatorioFIP215MPoderesOrgaos consulta a MSC por conta SICONFI do
+// consolidado do estado e de cada poder/órgão do exercício em uma única
+// consulta agrupada.
+func consultarRelatorioFIP215MPoderesOrgaos(parametros parametrosRelatorioFIP215M) (relatorioPoderesOrgaosFIP215M, *echo.HTTPError) {
+	var relatorio relatorioPoderesOrgaosFIP215M
+
+	parametros.PoderesOrgaos = true
+
+	poderesOrgaosSICONFI, erro := consultarPoderesOrgaosSICONFI(parametros.AnoExercicio)
+
+	if erro != nil {
+		return relatorio, erro
+	}
+
+	var sqlQuery strings.Builder
+
+	tmpl, err := template.New("queryMSCPoderesOrgaos").Funcs(FuncoesTemplate).Parse(queryDadosRelatorioFIP215M)
+
+	if err != nil {
+		log.Printf("consultarRelatorioFIP215MPoderesOrgaos: %v", err)
+		return relatorio, ErroMontagemTemplate
+	}
+
+	err = tmpl.Execute(&sqlQuery, parametros)
+
+	if err != nil {
+		log.Printf("consultarRelatorioFIP215MPoderesOrgaos: %v", err)
+		return relatorio, ErroExecucaoTemplate
+	}
+
+	compactSqlQuery := strings.Join(strings.Fields(sqlQuery.String()), " ")
+	log.Printf("consultarRelatorioFIP215MPoderesOrgaos: %s", compactSqlQuery)
+	rows, err := Db.Query(compactSqlQuery)
+
+	if err != nil {
+		log.Printf("consultarRelatorioFIP215MPoderesOrgaos: %v", err)
+		return relatorio, ErroConsultaBancoDados
+	}
+
+	defer rows.Close()
+
+	// O consolidado vem primeiro, seguido dos poderes/órgãos do exercício na
+	// ordem da ACWTB0803, inclusive os que não têm saldos no período.
+	relatorio.PoderesOrgaos = []poderOrgaoRelatorioFIP215M{{NomePoderOrgao: "CONSOLIDADO DO ESTADO"}}
+
+	indicesPoderesOrgaos := map[string]int{}
+
+	for _, poderOrgao := range poderesOrgaosSICONFI {
+		nomePoderOrgao := strconv.Itoa(poderOrgao.Codigo) + " - " + poderOrgao.Nome
+
+		indicesPoderesOrgaos[nomePoderOrgao] = len(relatorio.PoderesOrgaos)
+		relatorio.PoderesOrgaos = append(relatorio.PoderesOrgaos, poderOrgaoRelatorioFIP215M{
+			CodigoPoderOrgao: poderOrgao.Codigo,
+			NomePoderOrgao:   nomePoderOrgao,
+		})
+	}
+
+	for rows.Next() {
+		var poderOrgao string
+		var consolidado int
+		var dado dadoRelatorioFIP215M
+		var naturezaSaldo int
+
+		if err := rows.Scan(
+			&poderOrgao,
+			&consolidado,
+			&dado.CodigoContaSICONFI,
+			&naturezaSaldo,
+			&dado.ValorCredito,
+			&dado.ValorDebito,
+			&dado.SaldoAtual,
+		); err != nil {
+			log.Printf("consultarRelatorioFIP215MPoderesOrgaos: %v", err)
+			return relatorio, ErroConsultaLinhaBancoDados
+		}
+
+		dado.CodigoUnidadeOrcamentaria = dado.CodigoContaSICONFI
+		dado.NaturezaSaldo = NaturezaSaldoParaNome[naturezaSaldo]
+		dado.ValorSaldoAtual, dado.IndicadorSaldoAtual = IndicadorSaldo(dado.SaldoAtual)
+		dado.SaldoInvertido = SaldoInvertido(dado.NaturezaSaldo, dado.IndicadorSaldoAtual)
+
+		indice := 0
+
+		if consolidado == 0 {
+			var ok bool
+
+			// Saldos sem IC1 não pertencem a nenhum poder/órgão e aparecem
+			// apenas no consolidado.
+			if strings.TrimSpace(poderOrgao) == "" {
+				continue
+			}
+
+			// Saldos com IC1 que não corresponde a um poder/órgão da ACWTB0803
+			// são listados à parte, com o código do próprio IC1.
+			if indice, ok = indicesPoderesOrgaos[poderOrgao]; !ok {
+				codigo, _, _ := strings.Cut(poderOrgao, " - ")
+				codigoPoderOrgao, _ := strconv.Atoi(strings.TrimSpace(codigo))
+
+				indice = len(relatorio.PoderesOrgaos)
+				indicesPoderesOrgaos[poderOrgao] = indice
+				relatorio.PoderesOrgaos = append(relatorio.PoderesOrgaos, poderOrgaoRelatorioFIP215M{
+					CodigoPoderOrgao: codigoPoderOrgao,
+					NomePoderOrgao:   strings.TrimSpace(poderOrgao),
+				})
+			}
+		}
+
+		relatorio.PoderesOrgaos[indice].Dados = append(relatorio.PoderesOrgaos[indice].Dados, dado)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("consultarRelatorioFIP215MPoderesOrgaos: %v", err)
+		return relatorio, ErroRedeOuResultadoBancoDados
+	}
+	return relatorio, nil
+}
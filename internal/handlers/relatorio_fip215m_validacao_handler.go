@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type falhaValidacaoFIP215M struct {
+	Regra                     string                          `json:"regra"`
+	Descricao                 string                          `json:"descricao"`
+	CodigoContaSICONFI        string                          `json:"codigo_conta_siconfi,omitempty"`
+	InformacoesComplementares []informacaoComplementarFIP215M `json:"informacoes_complementares,omitempty"`
+	Diferenca                 float64                         `json:"diferenca,omitempty"`
+} // @name FalhaValidacaoFIP215M
+
+type relatorioValidacaoFIP215M struct {
+	Valida bool
+	Falhas []falhaValidacaoFIP215M
+} // @name RelatorioValidacaoFIP215M
+
+// RelatorioFIP215MValidacaoHandler godoc
+//
+// @Summary     FIP215M - Validação da MSC antes do Envio ao SICONFI
+// @Description Emite a MSC detalhada e aplica as regras de consistência do SICONFI: igualdade entre débitos e créditos e entre saldos devedores e credores por poder/órgão, informações complementares obrigatórias e permitidas por conta (configuradas nas regras do exercício) e saldos com natureza contrária à da conta
+// @Tags        Relatório
+// @Accept      json
+// @Produce     json
+// @Param       ano_exercicio      query    int true "Ano de Exercício"
+// @Param       mes_referencia     query    int true "Mês de Referência"                                Enums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)
+// @Param       mes_contabil       query    int true "Mês Contábil (1-Execução / 2-Apuração / 3-Todos)" Enums(1, 2, 3)
+// @Param       codigo_poder_orgao query    int true "Código do Poder/Órgão SICONFI"
+// @Success     200                {object} relatorioValidacaoFIP215M
+// @Failure     400                {object} Erro
+// @Failure     500                {object} Erro
+// @Router      /relatorio/fip_215m/validacao [get]
+func RelatorioFIP215MValidacaoHandler(c echo.Context) error {
+	/*** Validação dos Parâmetros ***/
+	parametros, erros := lerParametrosRelatorioFIP215M(c)
+
+	regras, ok := RegrasDoExercicio(parametros.AnoExercicio)
+
+	if len(erros) == 0 && !ok {
+		erros = append(erros, fmt.Sprintf("Não há regras de negócio configuradas para o exercício %d.", parametros.AnoExercicio))
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	erros, erro := poderOrgaoInexistenteFIP215M(parametros)
+
+	if erro != nil {
+		return erro
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+
+	parametros.InformacoesComplementares = true
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	msc, erro := consultarRelatorioFIP215M(parametros)
+
+	if erro != nil {
+		return erro
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	/*** Lógica Adicional ***/
+	validacao := validarRelatorioFIP215M(msc, regras, parametros.AnoExercicio)
+	/*** Lógica Adicional ***/
+
+	return c.JSON(http.StatusOK, validacao)
+}
+
+// validarRelatorioFIP215M aplica à MSC detalhada por informações
+// complementares as regras de consistência do SICONFI e as regras de
+// informações complementares do exercício.
+func validarRelatorioFIP215M(msc relatorioFIP215M, regras regrasExercicio, anoExercicio int) relatorioValidacaoFIP215M {
+	var validacao relatorioValidacaoFIP215M
+
+	naturezaContas := map[string]string{}
+
+	for _, dado := range msc.Dados {
+		naturezaContas[dado.CodigoContaSICONFI] = dado.NaturezaSaldo
+	}
+
+	// Débitos e créditos do período e saldos finais devedores e credores de
+	// cada poder/órgão (IC do tipo PO).
+	type totaisPoderOrgao struct {
+		Debito, Credito, SaldoDevedor, SaldoCredor float64
+	}
+
+	var poderesOrgaos []string
+	totais := map[string]*totaisPoderOrgao{}
+	combinacoesVerificadas := map[string]bool{}
+
+	for _, linha := range msc.Linhas {
+		poderOrgao := valorInformacaoComplementarFIP215M(linha.InformacoesComplementares, "PO")
+
+		if _, ok := totais[poderOrgao]; !ok {
+			poderesOrgaos = append(poderesOrgaos, poderOrgao)
+			totais[poderOrgao] = &totaisPoderOrgao{}
+		}
+
+		switch {
+		case linha.TipoValor == TipoValorMovimentoMSC && linha.NaturezaValor == "D":
+			totais[poderOrgao].Debito += linha.Valor
+		case linha.TipoValor == TipoValorMovimentoMSC && linha.NaturezaValor == "C":
+			totais[poderOrgao].Credito += linha.Valor
+		case linha.TipoValor == TipoValorSaldoFinalMSC && linha.NaturezaValor == "D":
+			totais[poderOrgao].SaldoDevedor += linha.Valor
+		case linha.TipoValor == TipoValorSaldoFinalMSC && linha.NaturezaValor == "C":
+			totais[poderOrgao].SaldoCredor += linha.Valor
+		}
+
+		regra, ok := regras.RegraInformacoesComplementaresMSC(linha.CodigoContaSICONFI)
+
+		if linha.TipoValor == TipoValorSaldoFinalMSC && !regra.PermiteSaldoInvertido && SaldoInvertido(naturezaContas[linha.CodigoContaSICONFI], linha.NaturezaValor) {
+			validacao.Falhas = append(validacao.Falhas, falhaValidacaoFIP215M{
+				Regra:                     "SALDO_INVERTIDO",
+				Descricao:                 fmt.Sprintf("A conta %s, de natureza %s, tem saldo final %s.", linha.CodigoContaSICONFI, strings.ToLower(naturezaContas[linha.CodigoContaSICONFI]), map[string]string{"D": "devedor", "C": "credor"}[linha.NaturezaValor]),
+				CodigoContaSICONFI:        linha.CodigoContaSICONFI,
+				InformacoesComplementares: linha.InformacoesComplementares,
+				Diferenca:                 linha.Valor,
+			})
+		}
+
+		// As informações complementares são verificadas uma vez por
+		// combinação, e não uma vez por tipo de valor.
+		combinacao := linha.CodigoContaSICONFI + fmt.Sprint(linha.InformacoesComplementares)
+
+		if combinacoesVerificadas[combinacao] {
+			continue
+		}
+
+		combinacoesVerificadas[combinacao] = true
+
+		if !ok {
+			validacao.Falhas = append(validacao.Falhas, falhaValidacaoFIP215M{
+				Regra:                     "CONTA_SEM_REGRA",
+				Descricao:                 fmt.Sprintf("Não há regra de informações complementares configurada para a conta %s no exercício %d.", linha.CodigoContaSICONFI, anoExercicio),
+				CodigoContaSICONFI:        linha.CodigoContaSICONFI,
+				InformacoesComplementares: linha.InformacoesComplementares,
+			})
+			continue
+		}
+
+		var tipos []string
+
+		for _, informacaoComplementar := range linha.InformacoesComplementares {
+			tipos = append(tipos, informacaoComplementar.Tipo)
+
+			if !slices.Contains(regra.Obrigatorias, informacaoComplementar.Tipo) && !slices.Contains(regra.Permitidas, informacaoComplementar.Tipo) {
+				validacao.Falhas = append(validacao.Falhas, falhaValidacaoFIP215M{
+					Regra:                     "INFORMACAO_COMPLEMENTAR_NAO_PERMITIDA",
+					Descricao:                 fmt.Sprintf("A informação complementar do tipo %s não é permitida na conta %s.", informacaoComplementar.Tipo, linha.CodigoContaSICONFI),
+					CodigoContaSICONFI:        linha.CodigoContaSICONFI,
+					InformacoesComplementares: linha.InformacoesComplementares,
+				})
+			}
+		}
+
+		for _, tipo := range regra.Obrigatorias {
+			if !slices.Contains(tipos, tipo) {
+				validacao.Falhas = append(validacao.Falhas, falhaValidacaoFIP215M{
+					Regra:                     "INFORMACAO_COMPLEMENTAR_OBRIGATORIA",
+					Descricao:                 fmt.Sprintf("A informação complementar do tipo %s é obrigatória na conta %s.", tipo, linha.CodigoContaSICONFI),
+					CodigoContaSICONFI:        linha.CodigoContaSICONFI,
+					InformacoesComplementares: linha.InformacoesComplementares,
+				})
+			}
+		}
+	}
+
+	sort.Strings(poderesOrgaos)
+
+	for _, poderOrgao := range poderesOrgaos {
+		informacoesComplementares := []informacaoComplementarFIP215M{{Tipo: "PO", Valor: poderOrgao}}
+		total := totais[poderOrgao]
+
+		if diferenca := math.Round((total.Debito-total.Credito)*100.0) / 100.0; diferenca != 0 {
+			validacao.Falhas = append(validacao.Falhas, falhaValidacaoFIP215M{
+				Regra:                     "DEBITOS_IGUAIS_CREDITOS",
+				Descricao:                 fmt.Sprintf("O total de débitos (%.2f) difere do total de créditos (%.2f) do poder/órgão %s.", total.Debito, total.Credito, poderOrgao),
+				InformacoesComplementares: informacoesComplementares,
+				Diferenca:                 diferenca,
+			})
+		}
+
+		if diferenca := math.Round((total.SaldoDevedor-total.SaldoCredor)*100.0) / 100.0; diferenca != 0 {
+			validacao.Falhas = append(validacao.Falhas, falhaValidacaoFIP215M{
+				Regra:                     "SALDOS_DEVEDORES_IGUAIS_CREDORES",
+				Descricao:                 fmt.Sprintf("O total dos saldos finais devedores (%.2f) difere do total dos saldos finais credores (%.2f) do poder/órgão %s.", total.SaldoDevedor, total.SaldoCredor, poderOrgao),
+				InformacoesComplementares: informacoesComplementares,
+				Diferenca:                 diferenca,
+			})
+		}
+	}
+
+	validacao.Valida = len(validacao.Falhas) == 0
+
+	return validacao
+}
+
+// valorInformacaoComplementarFIP215M retorna o valor da informação
+// complementar do tipo informado, ou vazio quando a linha não a tem.
+func valorInformacaoComplementarFIP215M(informacoesComplementares []informacaoComplementarFIP215M, tipo string) string {
+	for _, informacaoComplementar := range informacoesComplementares {
+		if informacaoComplementar.Tipo == tipo {
+			return informacaoComplementar.Valor
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestValidarRelatorioFIP215M(t *testing.T) {
+	regras := regrasExercicio{
+		InformacoesComplementaresMSC: []regraInformacoesComplementaresMSC{
+			{PrefixoConta: "1", Obrigatorias: []string{"PO"}, Permitidas: []string{"FP"}},
+			{PrefixoConta: "2", Obrigatorias: []string{"PO"}},
+			{PrefixoConta: "21", Obrigatorias: []string{"PO"}, PermiteSaldoInvertido: true},
+		},
+	}
+
+	dados := []dadoRelatorioFIP215M{
+		{CodigoContaSICONFI: "111110100", NaturezaSaldo: "DEVEDORA"},
+		{CodigoContaSICONFI: "221110100", NaturezaSaldo: "CREDORA"},
+		{CodigoContaSICONFI: "211110100", NaturezaSaldo: "CREDORA"},
+		{CodigoContaSICONFI: "311110100", NaturezaSaldo: "DEVEDORA"},
+	}
+
+	po := informacaoComplementarFIP215M{Tipo: "PO", Valor: "10131"}
+	fp := informacaoComplementarFIP215M{Tipo: "FP", Valor: "1"}
+	uo := informacaoComplementarFIP215M{Tipo: "UO", Valor: "11101"}
+
+	linha := func(conta string, informacoesComplementares []informacaoComplementarFIP215M, valor float64, tipoValor, naturezaValor string) linhaRelatorioFIP215M {
+		return linhaRelatorioFIP215M{CodigoContaSICONFI: conta, InformacoesComplementares: informacoesComplementares, Valor: valor, TipoValor: tipoValor, NaturezaValor: naturezaValor}
+	}
+
+	// Cada falha é descrita como "regra conta diferença".
+	casos := []struct {
+		nome     string
+		linhas   []linhaRelatorioFIP215M
+		esperado []string
+	}{
+		{
+			nome: "MSC válida",
+			linhas: []linhaRelatorioFIP215M{
+				linha("111110100", []informacaoComplementarFIP215M{po, fp}, 50, TipoValorMovimentoMSC, "D"),
+				linha("221110100", []informacaoComplementarFIP215M{po}, 50, TipoValorMovimentoMSC, "C"),
+				linha("111110100", []informacaoComplementarFIP215M{po, fp}, 100, TipoValorSaldoFinalMSC, "D"),
+				linha("221110100", []informacaoComplementarFIP215M{po}, 100, TipoValorSaldoFinalMSC, "C"),
+			},
+		},
+		{
+			nome: "débitos diferentes dos créditos",
+			linhas: []linhaRelatorioFIP215M{
+				linha("111110100", []informacaoComplementarFIP215M{po}, 50, TipoValorMovimentoMSC, "D"),
+				linha("221110100", []informacaoComplementarFIP215M{po}, 40, TipoValorMovimentoMSC, "C"),
+			},
+			esperado: []string{"DEBITOS_IGUAIS_CREDITOS  10.00"},
+		},
+		{
+			nome: "saldos devedores diferentes dos credores",
+			linhas: []linhaRelatorioFIP215M{
+				linha("111110100", []informacaoComplementarFIP215M{po}, 100, TipoValorSaldoFinalMSC, "D"),
+				linha("221110100", []informacaoComplementarFIP215M{po}, 100.5, TipoValorSaldoFinalMSC, "C"),
+			},
+			esperado: []string{"SALDOS_DEVEDORES_IGUAIS_CREDORES  -0.50"},
+		},
+		{
+			nome: "saldo invertido, exceto onde a regra o permite",
+			linhas: []linhaRelatorioFIP215M{
+				linha("111110100", []informacaoComplementarFIP215M{po}, 100, TipoValorSaldoFinalMSC, "C"),
+				linha("221110100", []informacaoComplementarFIP215M{po}, 60, TipoValorSaldoFinalMSC, "D"),
+				linha("211110100", []informacaoComplementarFIP215M{po}, 40, TipoValorSaldoFinalMSC, "D"),
+			},
+			esperado: []string{
+				"SALDO_INVERTIDO 111110100 100.00",
+				"SALDO_INVERTIDO 221110100 60.00",
+			},
+		},
+		{
+			nome: "informações complementares verificadas uma vez por combinação",
+			linhas: []linhaRelatorioFIP215M{
+				linha("111110100", []informacaoComplementarFIP215M{po, uo}, 0, TipoValorMovimentoMSC, "D"),
+				linha("111110100", []informacaoComplementarFIP215M{po, uo}, 0, TipoValorSaldoFinalMSC, "D"),
+				linha("221110100", []informacaoComplementarFIP215M{fp}, 0, TipoValorSaldoFinalMSC, "C"),
+			},
+			esperado: []string{
+				"INFORMACAO_COMPLEMENTAR_NAO_PERMITIDA 111110100 0.00",
+				"INFORMACAO_COMPLEMENTAR_NAO_PERMITIDA 221110100 0.00",
+				"INFORMACAO_COMPLEMENTAR_OBRIGATORIA 221110100 0.00",
+			},
+		},
+		{
+			nome: "conta sem regra de informações complementares",
+			linhas: []linhaRelatorioFIP215M{
+				linha("311110100", []informacaoComplementarFIP215M{po}, 0, TipoValorSaldoFinalMSC, "D"),
+			},
+			esperado: []string{"CONTA_SEM_REGRA 311110100 0.00"},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			validacao := validarRelatorioFIP215M(relatorioFIP215M{Dados: dados, Linhas: caso.linhas}, regras, 2024)
+
+			var falhas []string
+
+			for _, falha := range validacao.Falhas {
+				falhas = append(falhas, fmt.Sprintf("%s %s %.2f", falha.Regra, falha.CodigoContaSICONFI, falha.Diferenca))
+			}
+
+			if !reflect.DeepEqual(falhas, caso.esperado) {
+				t.Errorf("falhas = %q, esperado %q", falhas, caso.esperado)
+			}
+
+			if validacao.Valida != (len(caso.esperado) == 0) {
+				t.Errorf("valida = %v, esperado %v", validacao.Valida, len(caso.esperado) == 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// RelatorioRREOAnexo01Handler godoc
+//
+// @Summary     RREO - Anexo 1 - Balanço Orçamentário
+// @Description Projeta as contas de controle da aprovação e da execução do orçamento do balancete (FIP215) no Balanço Orçamentário do RREO, com as colunas do bimestre e até o bimestre: previsão inicial e atualizada, receitas realizadas e saldo da receita (realizada menos previsão atualizada), dotação inicial e atualizada, despesas empenhadas, liquidadas e pagas, o superávit/déficit e a execução dos restos a pagar não processados e processados. Como o balancete não detalha as naturezas de receita e de despesa, as linhas são as das contas de controle do Balanço Orçamentário configuradas nas regras do exercício (demonstrativo balanco_orcamentario), as mesmas do demonstrativo /demonstrativo/balanco_orcamentario
+// @Tags        Relatório
+// @Accept      json
+// @Produce     json,text/csv
+// @Param       ano_exercicio        query    int    true  "Ano de Exercício"
+// @Param       bimestre             query    int    true  "Bimestre"                                                                                     Enums(1, 2, 3, 4, 5, 6)
+// @Param       mes_contabil         query    int    true  "Mês Contábil (1-Execução / 2-Apuração / 3-Encerramento / 4-Todos)"                            Enums(1, 2, 3, 4)
+// @Param       unidade_gestora      query    []int  false "Códigos das Unidades Gestoras (separados por vírgula ou com o parâmetro repetido)"            collectionFormat(csv)
+// @Param       unidade_orcamentaria query    []int  false "Códigos das Unidades Orçamentárias (separados por vírgula ou com o parâmetro repetido)"       collectionFormat(csv)
+// @Param       orgao                query    []int  false "Códigos dos Órgãos (separados por vírgula ou com o parâmetro repetido)"                       collectionFormat(csv)
+// @Param       tipo_poder           query    []int  false "Tipos de Poder (1-Executivo / 2-Legislativo / 3-Judiciário / 4-Ministério Público / 5-Todos)" collectionFormat(csv) Enums(1, 2, 3, 4, 5)
+// @Param       tipo_administracao   query    int    false "Tipo de Administração (1-Diretas / 2-Indiretas / 3-Todas)"                                    Enums(1, 2, 3)
+// @Param       consolidado_rpps     query    bool   false "Consolidado RPPS?"                                                                            Enums(true, false)
+// @Param       eliminar_intra       query    bool   false "Eliminar as Contas Intra-OFSS (Classes 1 a 4 com 5º Dígito Igual a 2)?"                       Enums(true, false)
+// @Param       formato              query    string false "Formato de Saída (json / csv)"                                                                Enums(json, csv)
+// @Success     200                  {object} demonstrativoFiscal
+// @Failure     400                  {object} Erro
+// @Failure     500                  {object} Erro
+// @Router      /relatorio/rreo/anexo_01 [get]
+func RelatorioRREOAnexo01Handler(c echo.Context) error {
+	return demonstrativoFiscalHandler(c, "rreo_anexo_01", "balanco_orcamentario", "bimestre", []string{"periodo", "ate_periodo"})
+}
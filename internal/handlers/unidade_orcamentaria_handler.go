@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type unidadeOrcamentaria struct {
+	Codigo            string `json:"codigo"`
+	Nome              string `json:"nome"`
+	TipoAdministracao string `json:"tipo_administracao"`
+	CodigoOrgao       string `json:"codigo_orgao"`
+	NomeOrgao         string `json:"nome_orgao"`
+	TipoPoder         int    `json:"tipo_poder"`
+	NomeTipoPoder     string `json:"nome_tipo_poder"`
+} // @name UnidadeOrcamentaria
+
+type relatorioUnidadesOrcamentarias struct {
+	Dados []unidadeOrcamentaria
+} // @name RelatorioUnidadesOrcamentarias
+
+// UnidadeOrcamentariaHandler godoc
+//
+// @Summary     Unidades orçamentárias
+// @Description Lista as unidades orçamentárias do exercício, com o seu órgão, tipo de poder e tipo de administração
+// @Tags        Unidade
+// @Accept      json
+// @Produce     json
+// @Param       ano_exercicio      query    int true  "Ano de Exercício"
+// @Param       orgao              query    int false "Código do Órgão"
+// @Param       tipo_poder         query    int false "Tipo de Poder (1-Executivo / 2-Legislativo / 3-Judiciário / 4-Ministério Público)" Enums(1, 2, 3, 4)
+// @Param       tipo_administracao query    int false "Tipo de Administração (1-Diretas / 2-Indiretas)"                                     Enums(1, 2)
+// @Success     200                {object} relatorioUnidadesOrcamentarias
+// @Failure     400                {object} Erro
+// @Failure     500                {object} Erro
+// @Router      /unidade_orcamentaria [get]
+func UnidadeOrcamentariaHandler(c echo.Context) error {
+	/*** Parâmetros ***/
+	parametros := struct {
+		AnoExercicio      int
+		Orgao             int
+		TipoPoder         int
+		TipoAdministracao int
+	}{}
+	/*** Parâmetros ***/
+
+	/*** Validação dos Parâmetros ***/
+	valueBinder := echo.QueryParamsBinder(c)
+
+	var erros []string
+
+	if err := valueBinder.MustInt("ano_exercicio", &parametros.AnoExercicio).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o ano de exercício no parâmetro 'ano_exercicio'.")
+	}
+
+	if err := Validate.Var(parametros.AnoExercicio, fmt.Sprintf("gte=2010,lte=%d", time.Now().Year())); err != nil {
+		erros = append(erros, fmt.Sprintf("Por favor, forneça um ano de exercício válido entre 2010 e %d para o parâmetro 'ano_exercicio'.", time.Now().Year()))
+	}
+
+	if err := valueBinder.Int("orgao", &parametros.Orgao).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o órgão no parâmetro 'orgao'.")
+	}
+
+	if err := valueBinder.Int("tipo_poder", &parametros.TipoPoder).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o tipo de poder no parâmetro 'tipo_poder'.")
+	}
+
+	if err := Validate.Var(parametros.TipoPoder, "gte=0,lte=4"); err != nil {
+		erros = append(erros, "Por favor, forneça um tipo de poder válido entre 1 e 4 para o parâmetro 'tipo_poder'.")
+	}
+
+	if err := valueBinder.Int("tipo_administracao", &parametros.TipoAdministracao).BindError(); err != nil {
+		erros = append(erros, "Por favor, forneça o tipo de administração no parâmetro 'tipo_administracao'.")
+	}
+
+	if err := Validate.Var(parametros.TipoAdministracao, "gte=0,lte=2"); err != nil {
+		erros = append(erros, "Por favor, forneça um tipo de administração válido entre 1 e 2 para o parâmetro 'tipo_administracao'.")
+	}
+
+	if len(erros) > 0 {
+		return ErroValidacaoParametro(erros)
+	}
+	/*** Validação dos Parâmetros ***/
+
+	/*** Consulta no Banco de Dados ***/
+	var sqlQuery strings.Builder
+
+	var unidadesOrcamentarias relatorioUnidadesOrcamentarias
+
+	queryTemplate := `SELECT
+	                  UO.CD_UNIDADE_ORCAMENTARIA,
+	                  UO.DS_UNIDADE_ORCAMENTARIA,
+	                  NVL(TO_CHAR(UO.FLG_TIPO_ADM), ' '),
+	                  NVL(TO_CHAR(ORG.CD_ORGAO), ' '),
+	                  NVL(ORG.DS_ORGAO, ' '),
+	                  NVL(ORG.FLG_TP_PODER, 0)
+
+	                  FROM
+	                  UNIDADE_ORCAMENTARIA UO
+	                  LEFT JOIN
+	                  ORGAO ORG ON (ORG.ID_ORGAO = UO.ID_ORGAO)
+
+	                  WHERE UO.CD_EXERCICIO = {{.AnoExercicio}}
+
+	                  {{if .Orgao}}
+	                  AND ORG.CD_ORGAO = {{.Orgao}}
+	                  {{end}}
+
+	                  {{if .TipoPoder}}
+	                  AND ORG.FLG_TP_PODER = {{.TipoPoder}}
+	                  {{end}}
+
+	                  {{if .TipoAdministracao}}
+	                  AND LOWER(UO.FLG_TIPO_ADM) = '{{.TipoAdministracao}}'
+	                  {{end}}
+
+	                  ORDER BY UO.CD_UNIDADE_ORCAMENTARIA ASC`
+
+	tmpl, err := template.New("queryUnidadesOrcamentarias").Parse(queryTemplate)
+
+	if err != nil {
+		log.Printf("UnidadeOrcamentariaHandler: %v", err)
+		return ErroMontagemTemplate
+	}
+
+	err = tmpl.Execute(&sqlQuery, parametros)
+
+	if err != nil {
+		log.Printf("UnidadeOrcamentariaHandler: %v", err)
+		return ErroExecucaoTemplate
+	}
+
+	compactSqlQuery := strings.Join(strings.Fields(sqlQuery.String()), " ")
+	log.Printf("UnidadeOrcamentariaHandler: %s", compactSqlQuery)
+	rows, err := Db.Query(compactSqlQuery)
+
+	sqlQuery.Reset()
+
+	if err != nil {
+		log.Printf("UnidadeOrcamentariaHandler: %v", err)
+		return ErroConsultaBancoDados
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var dado unidadeOrcamentaria
+
+		if err := rows.Scan(
+			&dado.Codigo,
+			&dado.Nome,
+			&dado.TipoAdministracao,
+			&dado.CodigoOrgao,
+			&dado.NomeOrgao,
+			&dado.TipoPoder,
+		); err != nil {
+			log.Printf("UnidadeOrcamentariaHandler: %v", err)
+			return ErroConsultaLinhaBancoDados
+		}
+
+		dado.TipoAdministracao = strings.TrimSpace(dado.TipoAdministracao)
+		dado.CodigoOrgao = strings.TrimSpace(dado.CodigoOrgao)
+		dado.NomeOrgao = strings.TrimSpace(dado.NomeOrgao)
+		dado.NomeTipoPoder = TipoPoderParaNome[dado.TipoPoder]
+
+		unidadesOrcamentarias.Dados = append(unidadesOrcamentarias.Dados, dado)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("UnidadeOrcamentariaHandler: %v", err)
+		return ErroRedeOuResultadoBancoDados
+	}
+	/*** Consulta no Banco de Dados ***/
+
+	return c.JSON(http.StatusOK, unidadesOrcamentarias)
+}
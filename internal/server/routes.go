@@ -31,9 +31,30 @@ func (s *Server) RegisterRoutes() http.Handler {
 	}))
 
 	e.GET("/conta", handlers.ContaContabilHandler)
+	e.GET("/conta/mapeamento_siconfi", handlers.ContaMapeamentoSICONFIHandler)
+	e.GET("/demonstrativo/balanco_financeiro", handlers.DemonstrativoBalancoFinanceiroHandler)
+	e.GET("/demonstrativo/balanco_orcamentario", handlers.DemonstrativoBalancoOrcamentarioHandler)
+	e.GET("/demonstrativo/balanco_patrimonial", handlers.DemonstrativoBalancoPatrimonialHandler)
+	e.GET("/demonstrativo/fluxos_caixa", handlers.DemonstrativoFluxosCaixaHandler)
+	e.GET("/demonstrativo/variacoes_patrimoniais", handlers.DemonstrativoVariacoesPatrimoniaisHandler)
+	e.GET("/dominio/:nome", handlers.DominioHandler)
+	e.GET("/orgao", handlers.OrgaoHandler)
+	e.GET("/poder_orgao_siconfi", handlers.PoderOrgaoSICONFIHandler)
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 	e.GET("/relatorio/fip_215", handlers.RelatorioFIP215Handler)
+	e.GET("/relatorio/fip_215/comparativo", handlers.RelatorioFIP215ComparativoHandler)
+	e.GET("/relatorio/fip_215/composicao", handlers.RelatorioFIP215ComposicaoHandler)
+	e.GET("/relatorio/fip_215/serie_mensal", handlers.RelatorioFIP215SerieMensalHandler)
+	e.GET("/relatorio/fip_215/verificacao", handlers.RelatorioFIP215VerificacaoHandler)
+	e.GET("/regras_exercicio", handlers.RegrasExercicioHandler)
 	e.GET("/relatorio/fip_215m", handlers.RelatorioFIP215MHandler)
+	e.GET("/relatorio/fip_215m/conciliacao", handlers.RelatorioFIP215MConciliacaoHandler)
+	e.GET("/relatorio/fip_215m/eliminacao_intra", handlers.RelatorioFIP215MEliminacaoIntraHandler)
+	e.GET("/relatorio/fip_215m/poderes_orgaos", handlers.RelatorioFIP215MPoderesOrgaosHandler)
+	e.GET("/relatorio/fip_215m/validacao", handlers.RelatorioFIP215MValidacaoHandler)
+	e.GET("/relatorio/rreo/anexo_01", handlers.RelatorioRREOAnexo01Handler)
+	e.GET("/unidade_gestora", handlers.UnidadeGestoraHandler)
+	e.GET("/unidade_orcamentaria", handlers.UnidadeOrcamentariaHandler)
 
 	return e
 }
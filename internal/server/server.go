@@ -3,6 +3,7 @@ package server
 import (
 	"database/sql"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"strconv"
@@ -22,6 +23,14 @@ func NewServer() *http.Server {
 
 	handlers.StartDB()
 
+	if err := handlers.CarregarRegrasExercicio(); err != nil {
+		log.Printf("As regras de negócio por exercício não puderam ser carregadas, os relatórios que dependem delas serão recusados: %v", err)
+	}
+
+	if err := handlers.CarregarDominios(); err != nil {
+		log.Printf("Os domínios não puderam ser carregados, serão consultados sob demanda: %v", err)
+	}
+
 	NewServer := &Server{
 		port: port,
 		db:   handlers.Db,